@@ -0,0 +1,239 @@
+// Package archive writes items into one file per month, under
+// archive/YYYY/MM.json, with an archive.json index of the months.
+//
+// Each run merges the fetched items into the files already on disk, so the
+// archive keeps items after the platforms stop returning them. A month's
+// file is marked immutable on the first run after the month has ended, and
+// from then on it is never rewritten: its URL can be cached indefinitely.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"feed/feeds"
+)
+
+// Dir is the directory of the month files, relative to the output directory.
+const Dir = "archive"
+
+// IndexFile is the name of the index, relative to the output directory.
+const IndexFile = "archive.json"
+
+// Month is the content of a month file.
+type Month struct {
+	SchemaVersion int                     `json:"schema_version"`
+	Year          int                     `json:"year"`
+	Month         int                     `json:"month"`
+	Immutable     bool                    `json:"immutable"` // The month has ended and the file will not change again
+	Items         []feeds.FeedItem        `json:"items"`     // Newest first
+	Authors       map[string]feeds.Author `json:"authors,omitempty"`
+	Sources       map[string]feeds.Source `json:"sources,omitempty"`
+}
+
+// Entry describes a month in the index.
+type Entry struct {
+	Year      int    `json:"year"`
+	Month     int    `json:"month"`
+	Count     int    `json:"count"`
+	File      string `json:"file"` // Path relative to the output directory
+	Immutable bool   `json:"immutable"`
+}
+
+// Index is the content of archive.json.
+type Index struct {
+	SchemaVersion int     `json:"schema_version"`
+	Months        []Entry `json:"months"` // Newest first
+}
+
+// monthKey identifies a calendar month.
+type monthKey struct {
+	year  int
+	month time.Month
+}
+
+func (k monthKey) file() string {
+	return path.Join(Dir, fmt.Sprintf("%04d", k.year), fmt.Sprintf("%02d.json", int(k.month)))
+}
+
+// end is the first instant after the month, in UTC.
+func (k monthKey) end() time.Time {
+	return time.Date(k.year, k.month+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Write merges items into the month files under outputDir and rewrites the
+// index. Months are calendar months in UTC; items without a timestamp are
+// left out, and items are archived without their Permalink. Immutable
+// months are neither rewritten nor extended. now decides which months have
+// ended. Files are indented unless minify is set. It
+// returns the index and the files written, relative to outputDir.
+func Write(outputDir string, items []feeds.FeedItem, directory feeds.Directory, now time.Time, minify bool) (Index, []string, error) {
+	byMonth := make(map[monthKey][]feeds.FeedItem)
+	for _, item := range items {
+		if item.Timestamp.IsZero() {
+			continue
+		}
+		// Item files are pruned once the item leaves the feed, so a permalink
+		// would break in months that are never rewritten
+		item.Permalink = ""
+		t := item.Timestamp.UTC()
+		key := monthKey{t.Year(), t.Month()}
+		byMonth[key] = append(byMonth[key], item)
+	}
+
+	existing, err := existingMonths(outputDir)
+	if err != nil {
+		return Index{}, nil, err
+	}
+	for _, key := range existing {
+		if _, ok := byMonth[key]; !ok {
+			byMonth[key] = nil
+		}
+	}
+
+	index := Index{SchemaVersion: feeds.SchemaVersion}
+	var written []string
+	for key, monthItems := range byMonth {
+		filename := filepath.Join(outputDir, filepath.FromSlash(key.file()))
+		month, err := readMonth(filename)
+		if err != nil {
+			return Index{}, written, err
+		}
+		if month == nil {
+			month = &Month{SchemaVersion: feeds.SchemaVersion, Year: key.year, Month: int(key.month)}
+		}
+
+		if !month.Immutable {
+			month.SchemaVersion = feeds.SchemaVersion
+			month.Items = merge(month.Items, monthItems)
+			month.Authors, month.Sources = mergeTables(month.Authors, month.Sources, directory.For(monthItems))
+			month.Immutable = !now.Before(key.end())
+			if err := writeJSON(filename, month, minify); err != nil {
+				return Index{}, written, err
+			}
+			written = append(written, key.file())
+		}
+
+		index.Months = append(index.Months, Entry{
+			Year:      key.year,
+			Month:     int(key.month),
+			Count:     len(month.Items),
+			File:      key.file(),
+			Immutable: month.Immutable,
+		})
+	}
+
+	sort.Slice(index.Months, func(i, j int) bool {
+		a, b := index.Months[i], index.Months[j]
+		if a.Year != b.Year {
+			return a.Year > b.Year
+		}
+		return a.Month > b.Month
+	})
+	if index.Months == nil {
+		index.Months = []Entry{}
+	}
+	if err := writeJSON(filepath.Join(outputDir, IndexFile), index, minify); err != nil {
+		return Index{}, written, err
+	}
+	written = append(written, IndexFile)
+	return index, written, nil
+}
+
+// existingMonths lists the month files already under outputDir.
+func existingMonths(outputDir string) ([]monthKey, error) {
+	files, err := filepath.Glob(filepath.Join(outputDir, Dir, "[0-9][0-9][0-9][0-9]", "[0-9][0-9].json"))
+	if err != nil {
+		return nil, err
+	}
+	var keys []monthKey
+	for _, file := range files {
+		var year, month int
+		if _, err := fmt.Sscanf(filepath.Base(filepath.Dir(file)), "%d", &year); err != nil {
+			continue
+		}
+		if _, err := fmt.Sscanf(filepath.Base(file), "%d.json", &month); err != nil || month < 1 || month > 12 {
+			continue
+		}
+		keys = append(keys, monthKey{year, time.Month(month)})
+	}
+	return keys, nil
+}
+
+// readMonth loads a month file, or returns nil if there is none.
+func readMonth(filename string) (*Month, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var month Month
+	if err := json.Unmarshal(data, &month); err != nil {
+		return nil, fmt.Errorf("failed to read archive file %s: %w", filename, err)
+	}
+	return &month, nil
+}
+
+// merge adds the items not yet archived to archived, replacing archived
+// copies of items fetched again, and sorts the result newest first.
+func merge(archived, fetched []feeds.FeedItem) []feeds.FeedItem {
+	index := make(map[string]int, len(archived))
+	merged := append([]feeds.FeedItem(nil), archived...)
+	for i, item := range merged {
+		index[item.Key()] = i
+	}
+	for _, item := range fetched {
+		if i, ok := index[item.Key()]; ok {
+			merged[i] = item
+			continue
+		}
+		index[item.Key()] = len(merged)
+		merged = append(merged, item)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.After(merged[j].Timestamp)
+	})
+	return merged
+}
+
+// mergeTables adds the entries of directory to the month's author and source
+// tables, keeping those of items archived in earlier runs.
+func mergeTables(authors map[string]feeds.Author, sources map[string]feeds.Source, directory feeds.Directory) (map[string]feeds.Author, map[string]feeds.Source) {
+	if authors == nil {
+		authors = make(map[string]feeds.Author)
+	}
+	if sources == nil {
+		sources = make(map[string]feeds.Source)
+	}
+	for id, author := range directory.Authors {
+		authors[id] = author
+	}
+	for id, source := range directory.Sources {
+		sources[id] = source
+	}
+	return authors, sources
+}
+
+func writeJSON(filename string, v interface{}, minify bool) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	var data []byte
+	var err error
+	if minify {
+		data, err = json.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
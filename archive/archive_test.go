@@ -0,0 +1,149 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"feed/feeds"
+)
+
+func readMonthFile(t *testing.T, dir, name string) Month {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("Expected %s to be written: %v", name, err)
+	}
+	var month Month
+	if err := json.Unmarshal(data, &month); err != nil {
+		t.Fatalf("Failed to parse %s: %v", name, err)
+	}
+	return month
+}
+
+func ids(items []feeds.FeedItem) []string {
+	var result []string
+	for _, item := range items {
+		result = append(result, item.ID)
+	}
+	return result
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	items := []feeds.FeedItem{
+		{ID: "b", Platform: "x", PostContent: "June", Timestamp: time.Date(2025, 6, 30, 23, 0, 0, 0, time.UTC)},
+		{ID: "a", Platform: "x", PostContent: "May", Permalink: "items/20250503000000_ca978112ca1b.json", Timestamp: time.Date(2025, 5, 3, 0, 0, 0, 0, time.UTC)},
+		{ID: "c", Platform: "x", PostContent: "Undated"},
+	}
+	now := time.Date(2025, 6, 30, 23, 30, 0, 0, time.UTC)
+
+	index, written, err := Write(dir, items, feeds.BuildDirectory(items), now, false)
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	expected := []Entry{
+		{Year: 2025, Month: 6, Count: 1, File: "archive/2025/06.json", Immutable: false},
+		{Year: 2025, Month: 5, Count: 1, File: "archive/2025/05.json", Immutable: true},
+	}
+	if !reflect.DeepEqual(index.Months, expected) {
+		t.Errorf("Expected months %+v, got %+v", expected, index.Months)
+	}
+	if len(written) != 3 {
+		t.Errorf("Expected 2 month files and the index to be written, got %v", written)
+	}
+
+	var onDisk Index
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		t.Fatalf("Expected %s to be written: %v", IndexFile, err)
+	}
+	if err := json.Unmarshal(data, &onDisk); err != nil || !reflect.DeepEqual(onDisk.Months, expected) {
+		t.Errorf("Expected %s to hold the index, got %s", IndexFile, data)
+	}
+
+	month, err := readMonth(filepath.Join(dir, "archive", "2025", "05.json"))
+	if err != nil || month == nil || len(month.Items) != 1 {
+		t.Fatalf("Expected the May file to hold one item: %v", err)
+	}
+	if month.Items[0].Permalink != "" {
+		t.Errorf("Expected the archived item without a permalink, got %q", month.Items[0].Permalink)
+	}
+}
+
+func TestWrite_MergesUntilMonthEnds(t *testing.T) {
+	dir := t.TempDir()
+	june := func(day int) time.Time { return time.Date(2025, 6, day, 12, 0, 0, 0, time.UTC) }
+
+	first := []feeds.FeedItem{
+		{ID: "1", PostContent: "First", Timestamp: june(1)},
+		{ID: "2", PostContent: "Second", Timestamp: june(2)},
+	}
+	if _, _, err := Write(dir, first, feeds.BuildDirectory(first), june(2), false); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	// The first item is no longer fetched, the second is edited and a third is new.
+	second := []feeds.FeedItem{
+		{ID: "2", PostContent: "Second, edited", Timestamp: june(2)},
+		{ID: "3", PostContent: "Third", Timestamp: june(30)},
+	}
+	if _, _, err := Write(dir, second, feeds.BuildDirectory(second), time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), false); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	month := readMonthFile(t, dir, "archive/2025/06.json")
+	if got := ids(month.Items); !reflect.DeepEqual(got, []string{"3", "2", "1"}) {
+		t.Errorf("Expected items [3 2 1], got %v", got)
+	}
+	if month.Items[1].PostContent != "Second, edited" {
+		t.Errorf("Expected the refetched item to replace its archived copy, got %q", month.Items[1].PostContent)
+	}
+	if !month.Immutable {
+		t.Errorf("Expected the month to be immutable once it has ended")
+	}
+
+	// An ended month is not rewritten, even when its items are fetched again.
+	before, _ := os.ReadFile(filepath.Join(dir, "archive/2025/06.json"))
+	third := []feeds.FeedItem{{ID: "4", PostContent: "Late", Timestamp: june(15)}}
+	index, written, err := Write(dir, third, feeds.BuildDirectory(third), time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC), false)
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+	after, _ := os.ReadFile(filepath.Join(dir, "archive/2025/06.json"))
+	if string(before) != string(after) {
+		t.Errorf("Expected the immutable month file to be left unchanged")
+	}
+	if !reflect.DeepEqual(written, []string{IndexFile}) {
+		t.Errorf("Expected only the index to be written, got %v", written)
+	}
+	if len(index.Months) != 1 || index.Months[0].Count != 3 || !index.Months[0].Immutable {
+		t.Errorf("Expected the index to list the immutable month with 3 items, got %+v", index.Months)
+	}
+}
+
+func TestWrite_Empty(t *testing.T) {
+	dir := t.TempDir()
+	if _, _, err := Write(dir, nil, feeds.Directory{}, time.Now(), true); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		t.Fatalf("Expected %s to be written: %v", IndexFile, err)
+	}
+	var index map[string]interface{}
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+	if months, ok := index["months"].([]interface{}); !ok || len(months) != 0 {
+		t.Errorf("Expected an empty list of months, got %s", data)
+	}
+	if bytes.ContainsAny(data, "\n ") {
+		t.Errorf("Expected minified JSON, got %s", data)
+	}
+}
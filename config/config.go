@@ -1,9 +1,16 @@
 package config
 
 import (
+	"encoding/base64"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
 
 	"gopkg.in/yaml.v2"
+
+	"feed/feeds"
 )
 
 // Config represents the overall structure of the configuration file.
@@ -11,8 +18,120 @@ type Config struct {
 	Feeds                       FeedConfig `yaml:"feeds"`
 	OutputLimit                 int        `yaml:"output_limit"`
 	PageSize                    int        `yaml:"page_size"`
+	Pagination                  string     `yaml:"pagination"`
 	GenerateIndividualItemFiles bool       `yaml:"generate_individual_item_files"`
 	GeneratePlatformFeeds       bool       `yaml:"generate_platform_feeds"`
+	GenerateTagFeeds            bool       `yaml:"generate_tag_feeds"`
+	GenerateArchive             bool       `yaml:"generate_archive"`
+	GenerateNDJSON              bool       `yaml:"generate_ndjson"`
+	GenerateSQLite              bool       `yaml:"generate_sqlite"`
+	MinifyJSON                  bool       `yaml:"minify_json"`
+	DateFallback                string     `yaml:"date_fallback"`
+	HTTPCacheDir                string     `yaml:"http_cache_dir"`
+	// EngagementWeights weighs each engagement metric (likes, replies,
+	// shares, views, bookmarks or a platform-specific name) in the
+	// interactions score. Unlisted metrics count once, except views.
+	EngagementWeights map[string]float64 `yaml:"engagement_weights"`
+	// FoldThreads merges chains of an author replying to themselves into
+	// one thread item.
+	FoldThreads bool `yaml:"fold_threads"`
+	// Normalize sets the default content normalization steps, which
+	// platforms and RSS sources can override.
+	Normalize NormalizeConfig `yaml:"normalize"`
+	// LinkPreviews configures unfurling of the first external link in each
+	// item.
+	LinkPreviews LinkPreviewConfig `yaml:"link_previews"`
+	// Excerpts shortens content in the feed, platform feed, tag feed and
+	// archive outputs, and on the site's list pages; individual item files
+	// always keep the full content.
+	Excerpts ExcerptsConfig `yaml:"excerpts"`
+	// Site configures the static HTML pages written next to the JSON.
+	Site SiteConfig `yaml:"site"`
+	// Search configures the inverted index for client-side search.
+	Search SearchConfig `yaml:"search"`
+	// Compression configures precompressed copies of the output files.
+	Compression CompressionConfig `yaml:"compression"`
+	// Outputs lists the output writers to run, in order. When it is empty,
+	// the generate_* settings, site and search decide which writers run.
+	Outputs []OutputConfig `yaml:"outputs"`
+}
+
+// OutputConfig is an entry of the outputs list: the type of a writer and
+// its options, which override the global settings for that writer.
+type OutputConfig struct {
+	Type    string                 `yaml:"type"`
+	Options map[string]interface{} `yaml:",inline"`
+}
+
+// Decode sets the fields of v from the entry's options. Fields without an
+// option keep their value, and an option v has no field for is an error.
+func (c OutputConfig) Decode(v interface{}) error {
+	if len(c.Options) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(c.Options)
+	if err != nil {
+		return err
+	}
+	if err := yaml.UnmarshalStrict(data, v); err != nil {
+		return fmt.Errorf("invalid options for output %q: %w", c.Type, err)
+	}
+	return nil
+}
+
+// SearchConfig configures the client-side search index.
+type SearchConfig struct {
+	Enabled           bool     `yaml:"enabled"`
+	Fields            []string `yaml:"fields"`              // title, content, tags and username; all by default
+	Language          string   `yaml:"language"`            // "english" (stop words and stemming, the default) or "none"
+	TermShards        int      `yaml:"term_shards"`         // Number of files the terms are spread over
+	DocumentsPerShard int      `yaml:"documents_per_shard"` // 0 keeps all documents in one file
+}
+
+// CompressionConfig selects the compressed siblings written next to every
+// JSON and HTML output file.
+type CompressionConfig struct {
+	Gzip   bool `yaml:"gzip"`   // FILE.gz
+	Brotli bool `yaml:"brotli"` // FILE.br
+}
+
+// SiteConfig configures the static HTML site.
+type SiteConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	BaseURL     string `yaml:"base_url"`  // Public URL of the output directory, for absolute OpenGraph links
+	ThemeDir    string `yaml:"theme_dir"` // Templates (*.html) and style.css overriding the default theme
+}
+
+// ExcerptsConfig holds the content length limits of each output.
+type ExcerptsConfig struct {
+	Feed          ExcerptConfig `yaml:"feed"`           // feed.json or feed_page_N.json
+	PlatformFeeds ExcerptConfig `yaml:"platform_feeds"` // platforms/PLATFORM*.json
+	TagFeeds      ExcerptConfig `yaml:"tag_feeds"`      // tags/TAG*.json
+	Archive       ExcerptConfig `yaml:"archive"`        // archive/YYYY/MM.json
+}
+
+// ExcerptConfig limits the length of item content, in characters, in one
+// output. A limit of 0 keeps the full content.
+type ExcerptConfig struct {
+	MaxLength int            `yaml:"max_length"` // Limit for platforms not listed below
+	Platforms map[string]int `yaml:"platforms"`  // Per-platform limits, keyed by platform label
+}
+
+// Limit returns the content length limit for items from platform.
+func (c ExcerptConfig) Limit(platform string) int {
+	if limit, ok := c.Platforms[platform]; ok {
+		return limit
+	}
+	return c.MaxLength
+}
+
+// LinkPreviewConfig configures link preview unfurling.
+type LinkPreviewConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Timeout  time.Duration `yaml:"timeout"`  // Per-request timeout, e.g. "5s"; defaults to 5 seconds
+	Denylist []string      `yaml:"denylist"` // Domains (and their subdomains) whose links are never fetched, also through redirects
 }
 
 // FeedConfig defines which social media feeds are enabled.
@@ -30,13 +149,126 @@ type FeedConfig struct {
 
 // PlatformConfig is a generic configuration for a social media platform.
 type PlatformConfig struct {
-	Enabled bool `yaml:"enabled"`
+	Enabled   bool            `yaml:"enabled"`
+	Normalize NormalizeConfig `yaml:"normalize"`
 }
 
 // RSSConfig holds configuration specific to RSS feeds.
 type RSSConfig struct {
-	Enabled bool     `yaml:"enabled"`
-	URLs    []string `yaml:"urls"`
+	Enabled bool        `yaml:"enabled"`
+	URLs    []RSSSource `yaml:"urls"`
+	OPML    string      `yaml:"opml"` // Local path or URL of an OPML subscription list to import
+	// Normalize applies to every RSS source unless the source overrides it
+	Normalize NormalizeConfig `yaml:"normalize"`
+}
+
+// RSSSource is a single RSS subscription, either listed under urls or
+// imported from the OPML file. In YAML it is either a bare URL string or a
+// mapping with the fields below.
+type RSSSource struct {
+	URL       string            `yaml:"url"`
+	Name      string            `yaml:"name"`     // Display name, used when an item names no author
+	Platform  string            `yaml:"platform"` // Platform label for the items, e.g. "blog" or "podcast"; defaults to "rss"
+	Limit     int               `yaml:"limit"`    // Maximum number of items taken from this source; 0 means no limit
+	Author    string            `yaml:"author"`   // Username for every item, overriding the feed's own authors
+	Tags      []string          `yaml:"tags"`
+	Enabled   *bool             `yaml:"enabled"` // Defaults to true
+	Headers   map[string]string `yaml:"headers"`
+	Auth      *RSSAuth          `yaml:"auth"`
+	Normalize NormalizeConfig   `yaml:"normalize"`
+}
+
+// NormalizeConfig switches content normalization steps on or off. Steps
+// left unset inherit from the enclosing level: the global setting, then the
+// platform (or rss), then the RSS source.
+type NormalizeConfig struct {
+	SanitizeHTML       *bool `yaml:"sanitize_html"`
+	PlainText          *bool `yaml:"plain_text"`
+	DecodeEntities     *bool `yaml:"decode_entities"`
+	CollapseWhitespace *bool `yaml:"collapse_whitespace"`
+	ExpandShorteners   *bool `yaml:"expand_shorteners"`
+	StripTracking      *bool `yaml:"strip_tracking"`
+}
+
+// Merge returns c with the steps set in override replaced.
+func (c NormalizeConfig) Merge(override NormalizeConfig) NormalizeConfig {
+	pick := func(base, override *bool) *bool {
+		if override != nil {
+			return override
+		}
+		return base
+	}
+	return NormalizeConfig{
+		SanitizeHTML:       pick(c.SanitizeHTML, override.SanitizeHTML),
+		PlainText:          pick(c.PlainText, override.PlainText),
+		DecodeEntities:     pick(c.DecodeEntities, override.DecodeEntities),
+		CollapseWhitespace: pick(c.CollapseWhitespace, override.CollapseWhitespace),
+		ExpandShorteners:   pick(c.ExpandShorteners, override.ExpandShorteners),
+		StripTracking:      pick(c.StripTracking, override.StripTracking),
+	}
+}
+
+// RSSAuth holds credentials for a protected feed. Either Token (sent as a
+// bearer token) or Username/Password (sent as basic auth) should be set.
+// Values may reference environment variables, e.g. "${BLOG_TOKEN}", so that
+// secrets can be kept out of the config file.
+type RSSAuth struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Token    string `yaml:"token"`
+}
+
+// UnmarshalYAML accepts either a plain URL string or a full source mapping.
+func (s *RSSSource) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var url string
+	if err := unmarshal(&url); err == nil {
+		*s = RSSSource{URL: url}
+		return nil
+	}
+
+	type plain RSSSource
+	var source plain
+	if err := unmarshal(&source); err != nil {
+		return err
+	}
+	if source.URL == "" {
+		return fmt.Errorf("rss source is missing a url")
+	}
+	if source.Platform != "" && feeds.TagSlug(source.Platform) == "" {
+		return fmt.Errorf("rss source %s has platform %q without letters or digits", source.URL, source.Platform)
+	}
+	*s = RSSSource(source)
+	return nil
+}
+
+// IsEnabled reports whether the source should be fetched.
+func (s RSSSource) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// Header returns the extra request headers for the source, including any
+// Authorization header, with environment variables expanded.
+func (s RSSSource) Header() http.Header {
+	header := make(http.Header)
+	for key, value := range s.Headers {
+		header.Set(key, os.ExpandEnv(value))
+	}
+	if s.Auth != nil {
+		token := os.ExpandEnv(s.Auth.Token)
+		username := os.ExpandEnv(s.Auth.Username)
+		password := os.ExpandEnv(s.Auth.Password)
+		if token != "" {
+			header.Set("Authorization", "Bearer "+token)
+		} else if username != "" || password != "" {
+			header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+		}
+	}
+	return header
+}
+
+// Sources returns the RSS subscriptions listed directly in the config.
+func (c RSSConfig) Sources() []RSSSource {
+	return append([]RSSSource(nil), c.URLs...)
 }
 
 // LoadConfig reads the configuration from the specified YAML file.
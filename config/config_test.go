@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -44,8 +45,247 @@ feeds:
 	if len(cfg.Feeds.RSS.URLs) != 2 {
 		t.Errorf("Expected 2 RSS URLs, got %d", len(cfg.Feeds.RSS.URLs))
 	}
-	if cfg.Feeds.RSS.URLs[0] != "http://example.com/feed1.xml" {
-		t.Errorf("Expected first RSS URL to be 'http://example.com/feed1.xml', got '%s'", cfg.Feeds.RSS.URLs[0])
+	if cfg.Feeds.RSS.URLs[0].URL != "http://example.com/feed1.xml" {
+		t.Errorf("Expected first RSS URL to be 'http://example.com/feed1.xml', got '%s'", cfg.Feeds.RSS.URLs[0].URL)
+	}
+}
+
+func TestLoadConfig_RSSSources(t *testing.T) {
+	tempConfigFile := "test_rss_sources.yaml"
+	content := `
+feeds:
+  rss:
+    enabled: true
+    urls:
+      - "http://example.com/plain.xml"
+      - url: "http://example.com/podcast.xml"
+        name: "My Podcast"
+        platform: "podcast"
+        limit: 5
+        author: "Host"
+        tags: ["audio", "tech"]
+        headers:
+          X-Api-Key: "${TEST_RSS_KEY}"
+        auth:
+          username: "listener"
+          password: "${TEST_RSS_PASSWORD}"
+      - url: "http://example.com/old.xml"
+        enabled: false
+`
+	err := ioutil.WriteFile(tempConfigFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary config file: %v", err)
+	}
+	defer os.Remove(tempConfigFile)
+
+	t.Setenv("TEST_RSS_KEY", "key123")
+	t.Setenv("TEST_RSS_PASSWORD", "secret")
+
+	cfg, err := LoadConfig(tempConfigFile)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	sources := cfg.Feeds.RSS.Sources()
+	if len(sources) != 3 {
+		t.Fatalf("Expected 3 RSS sources, got %d", len(sources))
+	}
+
+	if sources[0].URL != "http://example.com/plain.xml" || !sources[0].IsEnabled() {
+		t.Errorf("Expected plain enabled source, got %+v", sources[0])
+	}
+
+	podcast := sources[1]
+	if podcast.Name != "My Podcast" || podcast.Platform != "podcast" || podcast.Limit != 5 || podcast.Author != "Host" {
+		t.Errorf("Podcast source settings not loaded: %+v", podcast)
+	}
+	if len(podcast.Tags) != 2 || podcast.Tags[0] != "audio" {
+		t.Errorf("Expected tags [audio tech], got %v", podcast.Tags)
+	}
+	header := podcast.Header()
+	if header.Get("X-Api-Key") != "key123" {
+		t.Errorf("Expected X-Api-Key header 'key123', got '%s'", header.Get("X-Api-Key"))
+	}
+	if header.Get("Authorization") != "Basic bGlzdGVuZXI6c2VjcmV0" {
+		t.Errorf("Expected basic Authorization header, got '%s'", header.Get("Authorization"))
+	}
+
+	if sources[2].IsEnabled() {
+		t.Errorf("Expected third source to be disabled")
+	}
+}
+
+func TestLoadConfig_RSSSourceMissingURL(t *testing.T) {
+	tempConfigFile := "test_rss_missing_url.yaml"
+	content := `
+feeds:
+  rss:
+    urls:
+      - name: "No URL"
+`
+	err := ioutil.WriteFile(tempConfigFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary config file: %v", err)
+	}
+	defer os.Remove(tempConfigFile)
+
+	_, err = LoadConfig(tempConfigFile)
+	if err == nil {
+		t.Errorf("Expected an error for an RSS source without a url, got nil")
+	}
+}
+
+func TestLoadConfig_RSSSourceInvalidPlatform(t *testing.T) {
+	tempConfigFile := "test_rss_invalid_platform.yaml"
+	content := `
+feeds:
+  rss:
+    urls:
+      - url: "https://example.com/feed.xml"
+        platform: "../.."
+`
+	err := ioutil.WriteFile(tempConfigFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary config file: %v", err)
+	}
+	defer os.Remove(tempConfigFile)
+
+	_, err = LoadConfig(tempConfigFile)
+	if err == nil {
+		t.Errorf("Expected an error for a platform label without letters or digits, got nil")
+	}
+}
+
+func TestLoadConfig_EngagementWeights(t *testing.T) {
+	tempConfigFile := "test_engagement_weights.yaml"
+	content := `
+engagement_weights:
+  replies: 2
+  views: 0.01
+`
+	err := ioutil.WriteFile(tempConfigFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary config file: %v", err)
+	}
+	defer os.Remove(tempConfigFile)
+
+	cfg, err := LoadConfig(tempConfigFile)
+	if err != nil {
+		t.Fatalf("LoadConfig returned an error: %v", err)
+	}
+	if cfg.EngagementWeights["replies"] != 2 {
+		t.Errorf("Expected replies weight 2, got %v", cfg.EngagementWeights["replies"])
+	}
+	if cfg.EngagementWeights["views"] != 0.01 {
+		t.Errorf("Expected views weight 0.01, got %v", cfg.EngagementWeights["views"])
+	}
+}
+
+func TestLoadConfig_Normalize(t *testing.T) {
+	tempConfigFile := "test_normalize.yaml"
+	content := `
+normalize:
+  sanitize_html: false
+  expand_shorteners: true
+feeds:
+  rss:
+    urls:
+      - url: "https://example.com/feed.xml"
+        normalize:
+          expand_shorteners: false
+`
+	err := ioutil.WriteFile(tempConfigFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary config file: %v", err)
+	}
+	defer os.Remove(tempConfigFile)
+
+	cfg, err := LoadConfig(tempConfigFile)
+	if err != nil {
+		t.Fatalf("LoadConfig returned an error: %v", err)
+	}
+
+	merged := cfg.Normalize.Merge(cfg.Feeds.RSS.Normalize).Merge(cfg.Feeds.RSS.URLs[0].Normalize)
+	if merged.SanitizeHTML == nil || *merged.SanitizeHTML {
+		t.Errorf("Expected sanitize_html to be inherited as false, got %v", merged.SanitizeHTML)
+	}
+	if merged.ExpandShorteners == nil || *merged.ExpandShorteners {
+		t.Errorf("Expected expand_shorteners to be overridden to false, got %v", merged.ExpandShorteners)
+	}
+	if merged.StripTracking != nil {
+		t.Errorf("Expected strip_tracking to be unset, got %v", *merged.StripTracking)
+	}
+}
+
+func TestLoadConfig_LinkPreviews(t *testing.T) {
+	tempConfigFile := "test_link_previews.yaml"
+	content := `
+link_previews:
+  enabled: true
+  timeout: 2s
+  denylist: ["example.com"]
+`
+	err := ioutil.WriteFile(tempConfigFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary config file: %v", err)
+	}
+	defer os.Remove(tempConfigFile)
+
+	cfg, err := LoadConfig(tempConfigFile)
+	if err != nil {
+		t.Fatalf("LoadConfig returned an error: %v", err)
+	}
+
+	if !cfg.LinkPreviews.Enabled {
+		t.Errorf("Expected link previews to be enabled")
+	}
+	if cfg.LinkPreviews.Timeout != 2*time.Second {
+		t.Errorf("Expected timeout 2s, got %s", cfg.LinkPreviews.Timeout)
+	}
+	if len(cfg.LinkPreviews.Denylist) != 1 || cfg.LinkPreviews.Denylist[0] != "example.com" {
+		t.Errorf("Expected denylist [example.com], got %v", cfg.LinkPreviews.Denylist)
+	}
+}
+
+func TestLoadConfig_Outputs(t *testing.T) {
+	tempConfigFile := "test_outputs.yaml"
+	content := `
+page_size: 10
+outputs:
+  - type: feed
+  - type: platforms
+    page_size: 5
+    excerpts:
+      max_length: 100
+`
+	err := ioutil.WriteFile(tempConfigFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create temporary config file: %v", err)
+	}
+	defer os.Remove(tempConfigFile)
+
+	cfg, err := LoadConfig(tempConfigFile)
+	if err != nil {
+		t.Fatalf("LoadConfig returned an error: %v", err)
+	}
+	if len(cfg.Outputs) != 2 || cfg.Outputs[0].Type != "feed" || cfg.Outputs[1].Type != "platforms" {
+		t.Fatalf("Expected outputs feed and platforms, got %+v", cfg.Outputs)
+	}
+
+	options := struct {
+		PageSize int           `yaml:"page_size"`
+		Excerpts ExcerptConfig `yaml:"excerpts"`
+		Other    string        `yaml:"other"`
+	}{PageSize: cfg.PageSize, Other: "kept"}
+	if err := cfg.Outputs[1].Decode(&options); err != nil {
+		t.Fatalf("Decode returned an error: %v", err)
+	}
+	if options.PageSize != 5 || options.Excerpts.MaxLength != 100 || options.Other != "kept" {
+		t.Errorf("Expected the entry's options on top of the defaults, got %+v", options)
+	}
+
+	if err := cfg.Outputs[1].Decode(&struct{}{}); err == nil {
+		t.Errorf("Expected an error for options the writer does not have")
 	}
 }
 
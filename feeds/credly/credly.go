@@ -7,6 +7,9 @@ import (
 	"feed/feeds"
 )
 
+// source describes the platform for the sources table.
+var source = feeds.Source{ID: "credly", Platform: "credly", Title: "Credly", HomeURL: "https://www.credly.com", IconURL: "https://www.credly.com/favicon.ico"}
+
 // CredlyFeed implements the SocialFeed interface for Credly.
 type CredlyFeed struct {
 	// Add any Credly-specific configuration here, e.g., API client
@@ -22,15 +25,23 @@ func (cf *CredlyFeed) Fetch() ([]feeds.FeedItem, error) {
 	log.Println("Simulating Credly feed fetch...")
 	// In a real implementation, this would involve calling the Credly API.
 	// For now, return a dummy item or an empty slice.
-	return []feeds.FeedItem{
+	items := []feeds.FeedItem{
 		{
-			Platform:     "credly",
-			PostContent:  "Earned 'Certified Kubernetes Administrator' badge!",
-			Username:     "credly_achiever",
-			MediaURL:     nil,
-			ProfileLink:  "https://www.credly.com/users/credly_achiever/badges",
-			Timestamp:    time.Now(),
-			Interactions: 25,
+			Platform:    "credly",
+			Kind:        feeds.KindBadge,
+			Title:       "Certified Kubernetes Administrator",
+			PostContent: "Earned 'Certified Kubernetes Administrator' badge!",
+			ContentText: "Earned 'Certified Kubernetes Administrator' badge!",
+			Username:    "credly_achiever",
+			MediaURL:    nil,
+			ProfileLink: "https://www.credly.com/users/credly_achiever/badges",
+			Author:      &feeds.Author{Handle: "credly_achiever", DisplayName: "credly_achiever", ProfileURL: "https://www.credly.com/users/credly_achiever/badges"},
+			Source:      &source,
+			Timestamp:   time.Now(),
+			Engagement:  &feeds.Engagement{Other: map[string]int{"endorsements": 25}},
 		},
-	}, nil
+	}
+	feeds.ScoreEngagement(items, nil)
+
+	return items, nil
 }
@@ -3,6 +3,8 @@ package credly
 import (
 	"testing"
 	"time"
+
+	"feed/feeds"
 )
 
 func TestNewCredlyFeed(t *testing.T) {
@@ -28,6 +30,9 @@ func TestCredlyFeed_Fetch(t *testing.T) {
 		if item.Platform != "credly" {
 			t.Errorf("Expected platform 'credly', got '%s'", item.Platform)
 		}
+		if item.Kind != feeds.KindBadge {
+			t.Errorf("Expected kind '%s', got '%s'", feeds.KindBadge, item.Kind)
+		}
 		if item.PostContent == "" {
 			t.Error("PostContent is empty")
 		}
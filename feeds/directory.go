@@ -0,0 +1,129 @@
+package feeds
+
+import "strings"
+
+// Author is the person or account behind an item.
+type Author struct {
+	ID          string `json:"id"`               // Stable key, e.g. "x:godev"; assigned by BuildDirectory if empty
+	Handle      string `json:"handle,omitempty"` // Account name without the leading @
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+	ProfileURL  string `json:"profile_url,omitempty"`
+}
+
+// Source is the feed or account an item was fetched from.
+type Source struct {
+	ID       string `json:"id"` // Stable key, e.g. "x" or "blog:https://example.com/feed.xml"; assigned by BuildDirectory if empty
+	Platform string `json:"platform"`
+	Title    string `json:"title,omitempty"` // Feed or platform title
+	HomeURL  string `json:"home_url,omitempty"`
+	FeedURL  string `json:"feed_url,omitempty"`
+	IconURL  string `json:"icon_url,omitempty"`
+}
+
+// Directory holds the authors and sources referenced by a set of items,
+// keyed by ID. Outputs write it as top-level tables that items point into
+// through AuthorID and SourceID.
+type Directory struct {
+	Authors map[string]Author `json:"authors,omitempty"`
+	Sources map[string]Source `json:"sources,omitempty"`
+}
+
+// BuildDirectory assigns AuthorID and SourceID on every item, including the
+// parts of threads, and collects the referenced authors and sources. Items
+// without an Author get one made from their Username and ProfileLink; items
+// without a Source get one for their platform. When several items describe
+// the same author or source, the first non-empty value of each field wins.
+func BuildDirectory(items []FeedItem) Directory {
+	directory := Directory{Authors: make(map[string]Author), Sources: make(map[string]Source)}
+	directory.add(items)
+	return directory
+}
+
+func (d Directory) add(items []FeedItem) {
+	for i := range items {
+		item := &items[i]
+
+		author := Author{DisplayName: item.Username, ProfileURL: item.ProfileLink}
+		if item.Author != nil {
+			author = *item.Author
+		}
+		if author.ID == "" {
+			author.ID = directoryID(item.Platform, author.Handle, author.DisplayName, author.ProfileURL)
+		}
+		d.Authors[author.ID] = mergeAuthor(d.Authors[author.ID], author)
+		item.AuthorID = author.ID
+
+		source := Source{Platform: item.Platform}
+		if item.Source != nil {
+			source = *item.Source
+		}
+		if source.Platform == "" {
+			source.Platform = item.Platform
+		}
+		if source.ID == "" {
+			source.ID = directoryID(source.Platform, source.FeedURL, source.HomeURL)
+		}
+		d.Sources[source.ID] = mergeSource(d.Sources[source.ID], source)
+		item.SourceID = source.ID
+
+		// The parts of a thread reference the tables too
+		d.add(item.Parts)
+	}
+}
+
+// For returns the part of the directory referenced by items.
+func (d Directory) For(items []FeedItem) Directory {
+	subset := Directory{Authors: make(map[string]Author), Sources: make(map[string]Source)}
+	for _, item := range items {
+		if author, ok := d.Authors[item.AuthorID]; ok {
+			subset.Authors[item.AuthorID] = author
+		}
+		if source, ok := d.Sources[item.SourceID]; ok {
+			subset.Sources[item.SourceID] = source
+		}
+	}
+	return subset
+}
+
+// directoryID builds a "platform:key" ID from the first non-empty key, or
+// just the platform if there is none.
+func directoryID(platform string, keys ...string) string {
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			return platform + ":" + strings.ToLower(key)
+		}
+	}
+	return platform
+}
+
+func mergeAuthor(existing, author Author) Author {
+	if existing.ID == "" {
+		return author
+	}
+	existing.Handle = firstNonEmpty(existing.Handle, author.Handle)
+	existing.DisplayName = firstNonEmpty(existing.DisplayName, author.DisplayName)
+	existing.AvatarURL = firstNonEmpty(existing.AvatarURL, author.AvatarURL)
+	existing.ProfileURL = firstNonEmpty(existing.ProfileURL, author.ProfileURL)
+	return existing
+}
+
+func mergeSource(existing, source Source) Source {
+	if existing.ID == "" {
+		return source
+	}
+	existing.Title = firstNonEmpty(existing.Title, source.Title)
+	existing.HomeURL = firstNonEmpty(existing.HomeURL, source.HomeURL)
+	existing.FeedURL = firstNonEmpty(existing.FeedURL, source.FeedURL)
+	existing.IconURL = firstNonEmpty(existing.IconURL, source.IconURL)
+	return existing
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
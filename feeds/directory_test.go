@@ -0,0 +1,44 @@
+package feeds
+
+import "testing"
+
+func TestBuildDirectory(t *testing.T) {
+	source := Source{ID: "x", Platform: "x", Title: "X"}
+	items := []FeedItem{
+		{Platform: "x", Username: "GoDev", Author: &Author{Handle: "GoDev", DisplayName: "GoDev"}, Source: &source},
+		{Platform: "x", Username: "GoDev", Author: &Author{Handle: "godev", AvatarURL: "https://x.com/godev.png"}, Source: &source},
+		{Platform: "rss", Username: "Jane", ProfileLink: "https://blog.example.com/post"},
+	}
+
+	directory := BuildDirectory(items)
+
+	expectedIDs := []struct{ author, source string }{
+		{"x:godev", "x"},
+		{"x:godev", "x"},
+		{"rss:jane", "rss"},
+	}
+	for i, expected := range expectedIDs {
+		if items[i].AuthorID != expected.author {
+			t.Errorf("Item %d AuthorID: Expected %s, got %s", i+1, expected.author, items[i].AuthorID)
+		}
+		if items[i].SourceID != expected.source {
+			t.Errorf("Item %d SourceID: Expected %s, got %s", i+1, expected.source, items[i].SourceID)
+		}
+	}
+
+	if len(directory.Authors) != 2 || len(directory.Sources) != 2 {
+		t.Fatalf("Expected 2 authors and 2 sources, got %d and %d", len(directory.Authors), len(directory.Sources))
+	}
+	godev := directory.Authors["x:godev"]
+	if godev.DisplayName != "GoDev" || godev.AvatarURL != "https://x.com/godev.png" {
+		t.Errorf("Expected merged author with display name and avatar, got %+v", godev)
+	}
+	if jane := directory.Authors["rss:jane"]; jane.ProfileURL != "https://blog.example.com/post" {
+		t.Errorf("Expected author made from the username and profile link, got %+v", jane)
+	}
+
+	subset := directory.For(items[2:])
+	if len(subset.Authors) != 1 || len(subset.Sources) != 1 {
+		t.Errorf("Expected 1 author and 1 source for the last item, got %+v", subset)
+	}
+}
@@ -0,0 +1,104 @@
+package feeds
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Engagement breaks down how people interacted with an item. Counts a
+// platform does not report are left at zero.
+type Engagement struct {
+	Likes     int `json:"likes,omitempty"`     // Likes, upvotes, kudos, hearts
+	Replies   int `json:"replies,omitempty"`   // Comments and replies
+	Shares    int `json:"shares,omitempty"`    // Shares, reposts, retweets
+	Views     int `json:"views,omitempty"`     // Views, impressions, plays
+	Bookmarks int `json:"bookmarks,omitempty"` // Bookmarks and saves
+	// Other holds platform-specific counts, e.g. "endorsements" on Credly.
+	Other map[string]int `json:"other,omitempty"`
+}
+
+// Metrics returns every count keyed by its metric name: likes, replies,
+// shares, views, bookmarks and the keys of Other.
+func (e Engagement) Metrics() map[string]int {
+	metrics := map[string]int{
+		"likes":     e.Likes,
+		"replies":   e.Replies,
+		"shares":    e.Shares,
+		"views":     e.Views,
+		"bookmarks": e.Bookmarks,
+	}
+	for name, count := range e.Other {
+		metrics[strings.ToLower(name)] += count
+	}
+	return metrics
+}
+
+// Score weighs each count and sums them, rounding to the nearest integer.
+func (e Engagement) Score(weights EngagementWeights) int {
+	var score float64
+	for name, count := range e.Metrics() {
+		score += float64(count) * weights.Weight(name)
+	}
+	return int(math.Round(score))
+}
+
+// add accumulates other's counts into e.
+func (e *Engagement) add(other Engagement) {
+	e.Likes += other.Likes
+	e.Replies += other.Replies
+	e.Shares += other.Shares
+	e.Views += other.Views
+	e.Bookmarks += other.Bookmarks
+	for name, count := range other.Other {
+		if e.Other == nil {
+			e.Other = make(map[string]int)
+		}
+		e.Other[name] += count
+	}
+}
+
+// EngagementWeights maps metric names to the weight they carry in the
+// interactions score. Metrics without an entry count once each.
+type EngagementWeights map[string]float64
+
+// DefaultEngagementWeights counts every interaction once but ignores views,
+// which would otherwise dwarf everything else on platforms that report them.
+func DefaultEngagementWeights() EngagementWeights {
+	return EngagementWeights{"views": 0}
+}
+
+// Weight returns the weight of the named metric.
+func (w EngagementWeights) Weight(name string) float64 {
+	if weight, ok := w[strings.ToLower(name)]; ok {
+		return weight
+	}
+	return 1
+}
+
+// ParseEngagementWeights validates configured weights and layers them over
+// DefaultEngagementWeights.
+func ParseEngagementWeights(overrides map[string]float64) (EngagementWeights, error) {
+	weights := DefaultEngagementWeights()
+	for name, weight := range overrides {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("invalid engagement weight %v for %q (expected a non-negative number)", weight, name)
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = weight
+	}
+	return weights, nil
+}
+
+// ScoreEngagement sets Interactions from Engagement on every item that has
+// one, using DefaultEngagementWeights when weights is nil. Items without an
+// engagement breakdown keep their Interactions as-is.
+func ScoreEngagement(items []FeedItem, weights EngagementWeights) {
+	if weights == nil {
+		weights = DefaultEngagementWeights()
+	}
+	for i := range items {
+		if items[i].Engagement != nil {
+			items[i].Interactions = items[i].Engagement.Score(weights)
+		}
+	}
+}
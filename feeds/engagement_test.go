@@ -0,0 +1,49 @@
+package feeds
+
+import "testing"
+
+func TestEngagement_Score(t *testing.T) {
+	engagement := Engagement{Likes: 10, Replies: 4, Shares: 2, Views: 1000, Bookmarks: 1, Other: map[string]int{"Kudos": 3}}
+
+	tests := []struct {
+		overrides map[string]float64
+		expected  int
+	}{
+		{nil, 20},
+		{map[string]float64{"replies": 2, "shares": 3}, 28},
+		{map[string]float64{"views": 0.01, "kudos": 0}, 27},
+		{map[string]float64{"LIKES": 0.5}, 15},
+	}
+
+	for i, tt := range tests {
+		weights, err := ParseEngagementWeights(tt.overrides)
+		if err != nil {
+			t.Fatalf("Case %d: ParseEngagementWeights returned an error: %v", i+1, err)
+		}
+		if got := engagement.Score(weights); got != tt.expected {
+			t.Errorf("Case %d Score: Expected %d, got %d", i+1, tt.expected, got)
+		}
+	}
+}
+
+func TestParseEngagementWeights_Negative(t *testing.T) {
+	if _, err := ParseEngagementWeights(map[string]float64{"likes": -1}); err == nil {
+		t.Errorf("Expected an error for a negative weight")
+	}
+}
+
+func TestScoreEngagement(t *testing.T) {
+	items := []FeedItem{
+		{Interactions: 7},
+		{Interactions: 7, Engagement: &Engagement{Likes: 2, Replies: 1, Views: 50}},
+	}
+
+	ScoreEngagement(items, nil)
+
+	if items[0].Interactions != 7 {
+		t.Errorf("Item 1 Interactions: Expected 7 to be kept without engagement, got %d", items[0].Interactions)
+	}
+	if items[1].Interactions != 3 {
+		t.Errorf("Item 2 Interactions: Expected 3, got %d", items[1].Interactions)
+	}
+}
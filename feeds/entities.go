@@ -0,0 +1,203 @@
+package feeds
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// EntityKind is the type of an Entity.
+type EntityKind string
+
+const (
+	EntityHashtag EntityKind = "hashtag"
+	EntityMention EntityKind = "mention"
+	EntityURL     EntityKind = "url"
+	EntityCashtag EntityKind = "cashtag"
+)
+
+// Entity is a hashtag, mention, link or cashtag found in an item's
+// ContentText. Start and End are offsets in UTF-16 code units, as JavaScript
+// indexes strings, with End exclusive.
+type Entity struct {
+	Kind  EntityKind `json:"kind"`
+	Text  string     `json:"text"`          // As written, e.g. "#golang" or "@godev"
+	Value string     `json:"value"`         // Tag without "#", handle, link, or upper-case symbol
+	Start int        `json:"start"`         // Offset of the first character
+	End   int        `json:"end"`           // Offset just past the last character
+	URL   string     `json:"url,omitempty"` // Profile link of a mention, if the platform is known
+}
+
+var (
+	// urlPattern matches http(s) links in plain text.
+	urlPattern     = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+	hashtagPattern = regexp.MustCompile(`#[\p{L}\p{M}\p{N}_]*\p{L}[\p{L}\p{M}\p{N}_]*`)
+	cashtagPattern = regexp.MustCompile(`\$[A-Za-z]{1,6}(?:[._][A-Za-z]{1,2})?`)
+)
+
+// mentionRule describes how a platform writes mentions.
+type mentionRule struct {
+	pattern *regexp.Regexp // The first group is the handle
+	profile string         // Profile link format, with %s for the handle
+}
+
+var (
+	defaultMentionRule = mentionRule{pattern: regexp.MustCompile(`@([A-Za-z0-9_]{1,30})`)}
+	// LinkedIn mentions are rich text that does not survive as plain text,
+	// so LinkedIn has no rule and its items get no mentions.
+	mentionRules = map[string]mentionRule{
+		"x":         {regexp.MustCompile(`@([A-Za-z0-9_]{1,15})`), "https://x.com/%s"},
+		"threads":   {regexp.MustCompile(`@([A-Za-z0-9_.]{1,30})`), "https://www.threads.net/@%s"},
+		"instagram": {regexp.MustCompile(`@([A-Za-z0-9_.]{1,30})`), "https://www.instagram.com/%s/"},
+		"reddit":    {regexp.MustCompile(`/?u/([A-Za-z0-9_-]{3,20})`), "https://www.reddit.com/user/%s"},
+	}
+)
+
+// ExtractEntities finds the entities in the ContentText of every item, and
+// of the parts of threads, and adds each hashtag to the item's Tags unless a
+// tag of the same name is already there.
+func ExtractEntities(items []FeedItem) {
+	for i := range items {
+		item := &items[i]
+		item.Entities = FindEntities(item.ContentText, item.Platform)
+		for _, entity := range item.Entities {
+			if entity.Kind == EntityHashtag {
+				item.Tags = AddTags(item.Tags, entity.Value)
+			}
+		}
+		ExtractEntities(item.Parts)
+	}
+}
+
+// FindEntities returns the entities in text in order of appearance. Mentions
+// follow the conventions of platform; unknown platforms get plain @handles.
+// Hashtags, mentions and cashtags inside links are ignored.
+func FindEntities(text, platform string) []Entity {
+	var entities []Entity
+	var links [][2]int
+	for _, span := range urlPattern.FindAllStringIndex(text, -1) {
+		link, _ := TrimURL(text[span[0]:span[1]])
+		end := span[0] + len(link)
+		links = append(links, [2]int{span[0], end})
+		entities = append(entities, Entity{Kind: EntityURL, Text: link, Value: link, Start: span[0], End: end})
+	}
+	insideLink := func(start, end int) bool {
+		for _, link := range links {
+			if start < link[1] && end > link[0] {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, span := range hashtagPattern.FindAllStringIndex(text, -1) {
+		if insideLink(span[0], span[1]) || !atBoundary(text, span[0], span[1], isWordRune) || precededBy(text, span[0], '&') {
+			continue
+		}
+		tag := text[span[0]:span[1]]
+		entities = append(entities, Entity{Kind: EntityHashtag, Text: tag, Value: tag[1:], Start: span[0], End: span[1]})
+	}
+
+	for _, span := range cashtagPattern.FindAllStringIndex(text, -1) {
+		if insideLink(span[0], span[1]) || !atBoundary(text, span[0], span[1], isWordRune) {
+			continue
+		}
+		symbol := text[span[0]:span[1]]
+		entities = append(entities, Entity{Kind: EntityCashtag, Text: symbol, Value: strings.ToUpper(symbol[1:]), Start: span[0], End: span[1]})
+	}
+
+	rule, ok := mentionRules[strings.ToLower(platform)]
+	if !ok && strings.EqualFold(platform, "linkedin") {
+		rule = mentionRule{}
+	} else if !ok {
+		rule = defaultMentionRule
+	}
+	if rule.pattern != nil {
+		for _, match := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := match[0], match[1]
+			handle := strings.TrimRight(text[match[2]:match[3]], ".")
+			end -= match[3] - match[2] - len(handle)
+			if insideLink(start, end) || !atBoundary(text, start, end, isHandleRune) {
+				continue
+			}
+			entity := Entity{Kind: EntityMention, Text: text[start:end], Value: handle, Start: start, End: end}
+			if rule.profile != "" {
+				entity.URL = fmt.Sprintf(rule.profile, handle)
+			}
+			entities = append(entities, entity)
+		}
+	}
+
+	sort.SliceStable(entities, func(i, j int) bool { return entities[i].Start < entities[j].Start })
+	// Convert byte offsets to UTF-16 offsets
+	for i := range entities {
+		entities[i].Start = utf16Len(text[:entities[i].Start])
+		entities[i].End = entities[i].Start + utf16Len(entities[i].Text)
+	}
+	return entities
+}
+
+// utf16Len returns the length of s in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// atBoundary reports whether text[start:end] is neither preceded nor
+// followed by a rune that would make it part of a longer word.
+func atBoundary(text string, start, end int, inWord func(rune) bool) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); before != utf8.RuneError && (inWord(before) || before == '@' || before == '$' || before == '#') {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); after != utf8.RuneError && (inWord(after) || after == '@') {
+		return false
+	}
+	return true
+}
+
+func precededBy(text string, start int, r rune) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	return before == r
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
+
+func isHandleRune(r rune) bool {
+	return isWordRune(r) || r == '/'
+}
+
+// ReplaceURLs passes every http(s) link in s through rewrite. Trailing
+// punctuation such as a full stop is not treated as part of the link.
+func ReplaceURLs(s string, rewrite func(string) string) string {
+	return urlPattern.ReplaceAllStringFunc(s, func(match string) string {
+		link, trailing := TrimURL(match)
+		return rewrite(link) + trailing
+	})
+}
+
+// TrimURL splits trailing punctuation off a link found in running text. A
+// closing parenthesis is kept when the link contains the opening one, as in
+// Wikipedia links.
+func TrimURL(match string) (link, trailing string) {
+	end := len(match)
+	for end > 0 {
+		c := match[end-1]
+		if strings.IndexByte(".,;:!?'\"", c) >= 0 ||
+			(c == ')' && strings.Count(match[:end], "(") < strings.Count(match[:end], ")")) ||
+			(c == ']' && !strings.Contains(match[:end], "[")) {
+			end--
+			continue
+		}
+		break
+	}
+	return match[:end], match[end:]
+}
@@ -0,0 +1,96 @@
+package feeds
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindEntities(t *testing.T) {
+	text := "Café ☕ #GoLang tips from @golang_dev: https://go.dev/blog/(intro). $AAPL up, not $100 or &#39;"
+
+	entities := FindEntities(text, "x")
+
+	expected := []Entity{
+		{Kind: EntityHashtag, Text: "#GoLang", Value: "GoLang", Start: 7, End: 14},
+		{Kind: EntityMention, Text: "@golang_dev", Value: "golang_dev", Start: 25, End: 36, URL: "https://x.com/golang_dev"},
+		{Kind: EntityURL, Text: "https://go.dev/blog/(intro)", Value: "https://go.dev/blog/(intro)", Start: 38, End: 65},
+		{Kind: EntityCashtag, Text: "$AAPL", Value: "AAPL", Start: 67, End: 72},
+	}
+	if !reflect.DeepEqual(entities, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entities)
+	}
+}
+
+func TestFindEntities_UTF16(t *testing.T) {
+	// The emoji is two UTF-16 code units, as String.prototype.slice counts it
+	text := "🎉 Thanks @godev, see https://go.dev"
+
+	entities := FindEntities(text, "x")
+
+	expected := []Entity{
+		{Kind: EntityMention, Text: "@godev", Value: "godev", Start: 10, End: 16, URL: "https://x.com/godev"},
+		{Kind: EntityURL, Text: "https://go.dev", Value: "https://go.dev", Start: 22, End: 36},
+	}
+	if !reflect.DeepEqual(entities, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, entities)
+	}
+}
+
+func TestFindEntities_Platforms(t *testing.T) {
+	tests := []struct {
+		platform string
+		text     string
+		expected []string // Values of the mentions found
+	}{
+		{"x", "cc @a_very_long_handle_indeed and mail@example.com", nil},
+		{"instagram", "Shot by @jane.doe.", []string{"jane.doe"}},
+		{"threads", "With @jane.doe", []string{"jane.doe"}},
+		{"reddit", "Thanks /u/some_user and u/other-user, not @nobody", []string{"some_user", "other-user"}},
+		{"linkedin", "Congrats @Jane Doe", nil},
+		{"blog", "Hello @friend", []string{"friend"}},
+	}
+
+	for _, test := range tests {
+		var mentions []string
+		for _, entity := range FindEntities(test.text, test.platform) {
+			if entity.Kind == EntityMention {
+				mentions = append(mentions, entity.Value)
+			}
+		}
+		if !reflect.DeepEqual(mentions, test.expected) {
+			t.Errorf("%s: Expected mentions %v, got %v", test.platform, test.expected, mentions)
+		}
+	}
+}
+
+func TestFindEntities_InsideLinks(t *testing.T) {
+	entities := FindEntities("See https://example.com/@user/#section?ref=$TAG", "x")
+
+	if len(entities) != 1 || entities[0].Kind != EntityURL {
+		t.Errorf("Expected only the link, got %+v", entities)
+	}
+}
+
+func TestExtractEntities(t *testing.T) {
+	items := []FeedItem{
+		{
+			Platform:    "x",
+			ContentText: "Shipping #golang and #Go today #GOLANG",
+			Tags:        []string{"go"},
+			Parts:       []FeedItem{{Platform: "x", ContentText: "#rust"}},
+		},
+	}
+
+	ExtractEntities(items)
+
+	if len(items[0].Entities) != 3 {
+		t.Errorf("Expected 3 entities, got %d", len(items[0].Entities))
+	}
+	expectedTags := []string{"go", "golang"}
+	if !reflect.DeepEqual(items[0].Tags, expectedTags) {
+		t.Errorf("Expected tags %v, got %v", expectedTags, items[0].Tags)
+	}
+	if len(items[0].Parts[0].Entities) != 1 || !reflect.DeepEqual(items[0].Parts[0].Tags, []string{"rust"}) {
+		t.Errorf("Expected the part's hashtag to be extracted, got %+v", items[0].Parts[0])
+	}
+}
@@ -0,0 +1,161 @@
+package feeds
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Ellipsis is appended to text shortened by Excerpt.
+const Ellipsis = "…"
+
+// Excerpt shortens text to at most max characters (Unicode code points),
+// including the trailing Ellipsis, and reports whether it was shortened.
+// It cuts at the end of a sentence if one falls in the second half of the
+// allowed length, otherwise at the last space. A link is never split: if the
+// cut falls inside one, the whole link is left out. A single word longer than
+// max is cut mid-word, but never inside a grapheme cluster such as an
+// accented letter or an emoji sequence. A max too small to hold any text
+// besides the Ellipsis keeps what fits without one. A max of zero or less
+// means no limit.
+func Excerpt(text string, max int) (string, bool) {
+	runes := []rune(text)
+	if max <= 0 || len(runes) <= max {
+		return text, false
+	}
+	budget := max - len([]rune(Ellipsis))
+	if budget < 1 {
+		if cut := graphemeBoundary(runes, max); cut > 0 {
+			return string(runes[:cut]), true
+		}
+		return Ellipsis, true
+	}
+
+	// Link spans, in code points, that must not be split
+	var links [][2]int
+	for _, span := range urlPattern.FindAllStringIndex(text, -1) {
+		link, _ := TrimURL(text[span[0]:span[1]])
+		start := len([]rune(text[:span[0]]))
+		links = append(links, [2]int{start, start + len([]rune(link))})
+	}
+	outsideLinks := func(cut int) int {
+		for _, link := range links {
+			if cut > link[0] && cut < link[1] {
+				return link[0]
+			}
+		}
+		return cut
+	}
+
+	sentence, word := 0, 0
+	for i := 1; i <= budget; i++ {
+		if i < len(runes) && unicode.IsSpace(runes[i]) {
+			word = i
+			if strings.ContainsRune(".!?", runes[i-1]) {
+				sentence = i
+			}
+		}
+	}
+
+	cut := budget
+	switch {
+	case sentence > 0 && sentence >= budget/2:
+		cut = sentence
+	case word > 0:
+		cut = word
+	default:
+		cut = graphemeBoundary(runes, budget)
+	}
+	cut = outsideLinks(cut)
+	if cut == 0 {
+		// The text starts with a link longer than max; keep it whole rather
+		// than split it
+		if len(links) == 0 || links[0][1] >= len(runes) {
+			return text, false
+		}
+		cut = links[0][1]
+	}
+
+	excerpt := strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(".,;:", r)
+	})
+	return excerpt + Ellipsis, true
+}
+
+// graphemeBoundary moves cut back until it no longer separates a character
+// from the combining marks, variation selectors, emoji modifiers, zero-width
+// joiners or regional indicator pair that belong to it.
+func graphemeBoundary(runes []rune, cut int) int {
+	for cut > 0 && cut < len(runes) {
+		r, prev := runes[cut], runes[cut-1]
+		switch {
+		case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), unicode.Is(unicode.Mc, r),
+			r == '\u200d', prev == '\u200d',
+			r >= '\ufe00' && r <= '\ufe0f',
+			r >= 0x1f3fb && r <= 0x1f3ff,
+			r >= 0xe0020 && r <= 0xe007f:
+			cut--
+		case isRegionalIndicator(r) && isRegionalIndicator(prev):
+			// Flags are pairs; count the indicators before the cut
+			n := 0
+			for i := cut - 1; i >= 0 && isRegionalIndicator(runes[i]); i-- {
+				n++
+			}
+			if n%2 == 0 {
+				return cut
+			}
+			cut--
+		default:
+			return cut
+		}
+	}
+	return cut
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// Excerpt returns a copy of the item with ContentText, PostContent and
+// Summary shortened to max characters, and the parts of a thread shortened
+// likewise. PostContent may hold markup, as for RSS items, so when it is too
+// long it is shortened as plain text, like ContentText. When anything was
+// cut, including only a part, ContentHTML is left out, since markup cannot
+// be cut safely, entities past the cut are dropped, and Truncated is set.
+// The full content belongs in the item's own file.
+func (i FeedItem) Excerpt(max int) FeedItem {
+	if max <= 0 {
+		return i
+	}
+	var cutText, cutPost, cutSummary, cutParts bool
+	i.ContentText, cutText = Excerpt(i.ContentText, max)
+	if len([]rune(i.PostContent)) > max {
+		i.PostContent, _ = Excerpt(HTMLToText(i.PostContent), max)
+		cutPost = true
+	}
+	i.Summary, cutSummary = Excerpt(i.Summary, max)
+
+	if len(i.Parts) > 0 {
+		parts := make([]FeedItem, len(i.Parts))
+		for n, part := range i.Parts {
+			parts[n] = part.Excerpt(max)
+			cutParts = cutParts || parts[n].Truncated
+		}
+		i.Parts = parts
+	}
+
+	if cutText || cutPost || cutSummary || cutParts {
+		i.Truncated = true
+		i.ContentHTML = ""
+	}
+	if cutText {
+		length := utf16Len(strings.TrimSuffix(i.ContentText, Ellipsis))
+		var kept []Entity
+		for _, entity := range i.Entities {
+			if entity.End <= length {
+				kept = append(kept, entity)
+			}
+		}
+		i.Entities = kept
+	}
+	return i
+}
@@ -0,0 +1,83 @@
+package feeds
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		max      int
+		expected string
+	}{
+		{"short text", "Hello world", 20, "Hello world"},
+		{"no limit", "Hello world", 0, "Hello world"},
+		{"word boundary", "The quick brown fox jumps over the lazy dog", 20, "The quick brown fox…"},
+		{"sentence boundary", "Go 1.23 is out. It adds iterators and more to the language.", 30, "Go 1.23 is out…"},
+		{"trailing punctuation", "First, second, third, fourth", 16, "First, second…"},
+		{"link is not split", "Read more at https://example.com/a/very/long/path today", 30, "Read more at…"},
+		{"long word", "Supercalifragilisticexpialidocious", 10, "Supercali…"},
+		{"combining mark", "Cafe\u0301s", 5, "Caf…"},
+		{"emoji sequence", "\U0001F469\u200d\U0001F4BB\U0001F469\u200d\U0001F4BB", 4, "\U0001F469\u200d\U0001F4BB…"},
+		{"flags", "🇯🇵🇫🇷🇩🇪", 4, "🇯🇵…"},
+		{"leading link", "https://example.com/a/very/long/path and more", 10, "https://example.com/a/very/long/path…"},
+		{"no room for an ellipsis", "abc", 1, "a"},
+		{"no room for a grapheme", "e\u0301tude", 1, "…"},
+		{"room for one character", "abc", 2, "a…"},
+	}
+
+	for _, test := range tests {
+		excerpt, truncated := Excerpt(test.text, test.max)
+		if excerpt != test.expected {
+			t.Errorf("%s: Expected %q, got %q", test.name, test.expected, excerpt)
+		}
+		if truncated != (excerpt != test.text) {
+			t.Errorf("%s: Expected truncated to be %v", test.name, excerpt != test.text)
+		}
+		if truncated && test.name != "leading link" && utf8.RuneCountInString(excerpt) > test.max {
+			t.Errorf("%s: Expected at most %d characters, got %d", test.name, test.max, utf8.RuneCountInString(excerpt))
+		}
+	}
+}
+
+func TestFeedItem_Excerpt(t *testing.T) {
+	text := "Shipping #golang today with a long explanation of #generics"
+	item := FeedItem{
+		Platform:    "blog",
+		PostContent: text,
+		ContentText: text,
+		ContentHTML: "<p>" + text + "</p>",
+		Entities:    FindEntities(text, "blog"),
+		Parts:       []FeedItem{{ContentText: text}},
+	}
+
+	excerpt := item.Excerpt(30)
+
+	if excerpt.ContentText != "Shipping #golang today with a…" || excerpt.PostContent != excerpt.ContentText {
+		t.Errorf("Expected shortened text, got %q and %q", excerpt.ContentText, excerpt.PostContent)
+	}
+	if !excerpt.Truncated || excerpt.ContentHTML != "" {
+		t.Errorf("Expected a truncated item without HTML, got truncated=%v html=%q", excerpt.Truncated, excerpt.ContentHTML)
+	}
+	if len(excerpt.Entities) != 1 || excerpt.Entities[0].Value != "golang" {
+		t.Errorf("Expected only the entity before the cut, got %+v", excerpt.Entities)
+	}
+	if !excerpt.Parts[0].Truncated {
+		t.Errorf("Expected the thread part to be shortened too")
+	}
+	if item.ContentText != text || item.Parts[0].Truncated {
+		t.Errorf("Expected the original item to be unchanged")
+	}
+
+	// A thread whose joined text fits but whose parts do not is still cut
+	thread := FeedItem{ContentText: "Short", ContentHTML: "<p>Short</p>", Parts: []FeedItem{{ContentText: text}}}
+	if cut := thread.Excerpt(30); !cut.Truncated || cut.ContentHTML != "" {
+		t.Errorf("Expected a thread with shortened parts to be truncated, got truncated=%v html=%q", cut.Truncated, cut.ContentHTML)
+	}
+
+	if unchanged := item.Excerpt(0); unchanged.Truncated || unchanged.ContentHTML == "" {
+		t.Errorf("Expected no limit to keep the full item")
+	}
+}
@@ -1,17 +1,119 @@
 package feeds
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the JSON output schema. It is bumped once
+// per release that adds fields or changes their meaning, so consumers can
+// migrate; specs/README.md lists what each version changed.
+const SchemaVersion = 2
+
+// ItemKind classifies what a FeedItem represents.
+type ItemKind string
+
+const (
+	KindPost     ItemKind = "post"
+	KindRepost   ItemKind = "repost"
+	KindReply    ItemKind = "reply"
+	KindArticle  ItemKind = "article"
+	KindActivity ItemKind = "activity"
+	KindBadge    ItemKind = "badge"
+	KindBook     ItemKind = "book"
+	KindVideo    ItemKind = "video"
+	KindThread   ItemKind = "thread" // A post and its author's replies, folded by FoldThreads
+)
 
 // FeedItem represents a standardized social media post or RSS item.
 type FeedItem struct {
-	Platform     string    `json:"platform"`
-	PostContent  string    `json:"post_content"`
-	Username     string    `json:"username"`
-	MediaURL     *string   `json:"media_url"` // Use pointer for nullable string
-	ProfileLink  string    `json:"profile_link"`
-	Timestamp    time.Time `json:"timestamp"`
-	Interactions int       `json:"interactions"`
-	Permalink    string    `json:"permalink,omitempty"` // URL to the individual item's JSON file, if generated
+	ID           string       `json:"id,omitempty"` // "platform:native-id", used by the references below
+	Platform     string       `json:"platform"`
+	SourceID     string       `json:"source_id,omitempty"` // Key into the sources table
+	Source       *Source      `json:"-"`                   // Written to the sources table; see BuildDirectory
+	Kind         ItemKind     `json:"kind,omitempty"`
+	Title        string       `json:"title,omitempty"`
+	PostContent  string       `json:"post_content"`           // Kept for compatibility; prefer the fields below
+	ContentText  string       `json:"content_text,omitempty"` // Plain-text body
+	ContentHTML  string       `json:"content_html,omitempty"` // HTML body, if the source provides one
+	Summary      string       `json:"summary,omitempty"`      // Short plain-text summary, if distinct from the body
+	Truncated    bool         `json:"truncated,omitempty"`    // The content above is an excerpt; see Excerpt
+	Language     string       `json:"language,omitempty"`     // Language tag, e.g. "en" or "ja-JP"
+	Username     string       `json:"username"`
+	AuthorID     string       `json:"author_id,omitempty"` // Key into the authors table
+	Author       *Author      `json:"-"`                   // Written to the authors table; see BuildDirectory
+	MediaURL     *string      `json:"media_url"`           // First image in Media, kept for compatibility; use SetMedia
+	Media        []Attachment `json:"media,omitempty"`
+	ProfileLink  string       `json:"profile_link"`
+	Timestamp    time.Time    `json:"timestamp"`
+	Interactions int          `json:"interactions"`         // Weighted sum of Engagement; see ScoreEngagement
+	Engagement   *Engagement  `json:"engagement,omitempty"` // Per-metric breakdown, if the platform reports one
+	Tags         []string     `json:"tags,omitempty"`
+	Entities     []Entity     `json:"entities,omitempty"`     // Hashtags, mentions, links and cashtags in ContentText
+	LinkPreview  *LinkPreview `json:"link_preview,omitempty"` // Metadata of the first external link, if unfurled
+	InReplyTo    string       `json:"in_reply_to,omitempty"`  // ID of the item this replies to
+	QuotedItem   string       `json:"quoted_item,omitempty"`  // ID of the item this quotes
+	ThreadID     string       `json:"thread_id,omitempty"`    // ID of the first item in the conversation
+	Parts        []FeedItem   `json:"parts,omitempty"`        // The posts of a KindThread item, in order
+	Permalink    string       `json:"permalink,omitempty"`    // URL to the individual item's JSON file, if generated
+}
+
+// Attachment is a media file (image, video, audio) attached to an item.
+type Attachment struct {
+	URL       string  `json:"url"`
+	Type      string  `json:"type,omitempty"` // MIME type, e.g. "image/jpeg"
+	Width     int     `json:"width,omitempty"`
+	Height    int     `json:"height,omitempty"`
+	Duration  float64 `json:"duration,omitempty"` // Seconds, for audio and video
+	Length    int64   `json:"length,omitempty"`   // Size in bytes
+	Alt       string  `json:"alt,omitempty"`
+	Thumbnail string  `json:"thumbnail,omitempty"` // Preview image, e.g. a video poster
+}
+
+// LinkPreview describes the page behind a link, as read from its OpenGraph,
+// Twitter Card or oEmbed metadata.
+type LinkPreview struct {
+	URL         string `json:"url"` // The link as it appears in the item
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+// IsImage reports whether the attachment is an image.
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.Type, "image/")
+}
+
+// SetMedia sets the item's attachments and derives MediaURL from them: the
+// first image, or failing that the first attachment's thumbnail.
+func (i *FeedItem) SetMedia(media []Attachment) {
+	i.Media = media
+	i.MediaURL = nil
+	for _, attachment := range media {
+		if attachment.IsImage() {
+			url := attachment.URL
+			i.MediaURL = &url
+			return
+		}
+	}
+	for _, attachment := range media {
+		if attachment.Thumbnail != "" {
+			url := attachment.Thumbnail
+			i.MediaURL = &url
+			return
+		}
+	}
+}
+
+// Key identifies the item across runs: by ID, or for items without one by
+// platform, time and content.
+func (i FeedItem) Key() string {
+	if i.ID != "" {
+		return i.ID
+	}
+	return fmt.Sprintf("%s|%s|%s", i.Platform, i.Timestamp.UTC().Format(time.RFC3339Nano), i.PostContent)
 }
 
 // SocialFeed defines the interface for fetching social media feed items.
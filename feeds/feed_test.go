@@ -0,0 +1,30 @@
+package feeds
+
+import "testing"
+
+func TestFeedItem_SetMedia(t *testing.T) {
+	tests := []struct {
+		media    []Attachment
+		expected string
+	}{
+		{[]Attachment{{URL: "https://example.com/clip.mp4", Type: "video/mp4"}, {URL: "https://example.com/a.jpg", Type: "image/jpeg"}}, "https://example.com/a.jpg"},
+		{[]Attachment{{URL: "https://example.com/ep.mp3", Type: "audio/mpeg", Thumbnail: "https://example.com/cover.png"}}, "https://example.com/cover.png"},
+		{[]Attachment{{URL: "https://example.com/ep.mp3", Type: "audio/mpeg"}}, ""},
+		{nil, ""},
+	}
+
+	for i, tt := range tests {
+		item := FeedItem{MediaURL: new(string)}
+		item.SetMedia(tt.media)
+		got := ""
+		if item.MediaURL != nil {
+			got = *item.MediaURL
+		}
+		if got != tt.expected {
+			t.Errorf("Case %d MediaURL: Expected %q, got %q", i+1, tt.expected, got)
+		}
+		if len(item.Media) != len(tt.media) {
+			t.Errorf("Case %d Media: Expected %d attachments, got %d", i+1, len(tt.media), len(item.Media))
+		}
+	}
+}
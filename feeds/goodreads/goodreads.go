@@ -7,6 +7,9 @@ import (
 	"feed/feeds"
 )
 
+// source describes the platform for the sources table.
+var source = feeds.Source{ID: "goodreads", Platform: "goodreads", Title: "Goodreads", HomeURL: "https://www.goodreads.com", IconURL: "https://www.goodreads.com/favicon.ico"}
+
 // GoodreadsFeed implements the SocialFeed interface for Goodreads.
 type GoodreadsFeed struct {
 	// Add any Goodreads-specific configuration here, e.g., API client
@@ -22,15 +25,23 @@ func (gf *GoodreadsFeed) Fetch() ([]feeds.FeedItem, error) {
 	log.Println("Simulating Goodreads feed fetch...")
 	// In a real implementation, this would involve calling the Goodreads API.
 	// For now, return a dummy item or an empty slice.
-	return []feeds.FeedItem{
+	items := []feeds.FeedItem{
 		{
-			Platform:     "goodreads",
-			PostContent:  "Finished reading 'The Hitchhiker's Guide to the Galaxy'. Highly recommend!",
-			Username:     "goodreads_reader",
-			MediaURL:     nil,
-			ProfileLink:  "https://www.goodreads.com/user/show/goodreads_reader",
-			Timestamp:    time.Now(),
-			Interactions: 42,
+			Platform:    "goodreads",
+			Kind:        feeds.KindBook,
+			Title:       "The Hitchhiker's Guide to the Galaxy",
+			PostContent: "Finished reading 'The Hitchhiker's Guide to the Galaxy'. Highly recommend!",
+			ContentText: "Finished reading 'The Hitchhiker's Guide to the Galaxy'. Highly recommend!",
+			Username:    "goodreads_reader",
+			MediaURL:    nil,
+			ProfileLink: "https://www.goodreads.com/user/show/goodreads_reader",
+			Author:      &feeds.Author{Handle: "goodreads_reader", DisplayName: "goodreads_reader", ProfileURL: "https://www.goodreads.com/user/show/goodreads_reader"},
+			Source:      &source,
+			Timestamp:   time.Now(),
+			Engagement:  &feeds.Engagement{Likes: 42},
 		},
-	}, nil
+	}
+	feeds.ScoreEngagement(items, nil)
+
+	return items, nil
 }
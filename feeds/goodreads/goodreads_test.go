@@ -3,6 +3,8 @@ package goodreads
 import (
 	"testing"
 	"time"
+
+	"feed/feeds"
 )
 
 func TestNewGoodreadsFeed(t *testing.T) {
@@ -28,6 +30,9 @@ func TestGoodreadsFeed_Fetch(t *testing.T) {
 		if item.Platform != "goodreads" {
 			t.Errorf("Expected platform 'goodreads', got '%s'", item.Platform)
 		}
+		if item.Kind != feeds.KindBook {
+			t.Errorf("Expected kind '%s', got '%s'", feeds.KindBook, item.Kind)
+		}
 		if item.PostContent == "" {
 			t.Error("PostContent is empty")
 		}
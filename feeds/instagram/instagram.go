@@ -9,6 +9,9 @@ import (
 	"feed/feeds"
 )
 
+// source describes the platform for the sources table.
+var source = feeds.Source{ID: "instagram", Platform: "instagram", Title: "Instagram", HomeURL: "https://www.instagram.com", IconURL: "https://www.instagram.com/favicon.ico"}
+
 // InstagramFeed implements the SocialFeed interface for Instagram.
 type InstagramFeed struct{}
 
@@ -34,29 +37,40 @@ func (i *InstagramFeed) Fetch() ([]feeds.FeedItem, error) {
 	// Simulate fetching a few items
 	items := []feeds.FeedItem{
 		{
-			Platform:     "instagram",
-			PostContent:  "Beautiful sunset views from the beach! #travel #photography",
-			Username:     "TravelBug",
-			MediaURL:     stringPtr("https://instagram.com/p/sunset.jpg"),
-			ProfileLink:  "https://instagram.com/travelbug",
-			Timestamp:    time.Now().Add(-5 * time.Hour),
-			Interactions: 350,
+			Platform:    "instagram",
+			Kind:        feeds.KindPost,
+			PostContent: "Beautiful sunset views from the beach! #travel #photography",
+			ContentText: "Beautiful sunset views from the beach! #travel #photography",
+			Username:    "TravelBug",
+			ProfileLink: "https://instagram.com/travelbug",
+			Author:      &feeds.Author{Handle: "travelbug", DisplayName: "TravelBug", ProfileURL: "https://instagram.com/travelbug"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-5 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 320, Replies: 22, Bookmarks: 8},
 		},
 		{
-			Platform:     "instagram",
-			PostContent:  "New recipe alert! Delicious homemade pasta.",
-			Username:     "FoodieChef",
-			MediaURL:     stringPtr("https://instagram.com/p/pasta.jpg"),
-			ProfileLink:  "https://instagram.com/foodiechef",
-			Timestamp:    time.Now().Add(-20 * time.Hour),
-			Interactions: 280,
+			Platform:    "instagram",
+			Kind:        feeds.KindPost,
+			PostContent: "New recipe alert! Delicious homemade pasta.",
+			ContentText: "New recipe alert! Delicious homemade pasta.",
+			Username:    "FoodieChef",
+			ProfileLink: "https://instagram.com/foodiechef",
+			Author:      &feeds.Author{Handle: "foodiechef", DisplayName: "FoodieChef", ProfileURL: "https://instagram.com/foodiechef"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-20 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 240, Replies: 25, Bookmarks: 15},
 		},
 	}
+	// The first post is a carousel
+	items[0].SetMedia([]feeds.Attachment{
+		{URL: "https://instagram.com/p/sunset.jpg", Type: "image/jpeg", Width: 1080, Height: 1350, Alt: "Sun setting over the ocean"},
+		{URL: "https://instagram.com/p/beach.jpg", Type: "image/jpeg", Width: 1080, Height: 1350, Alt: "Footprints in the sand"},
+	})
+	items[1].SetMedia([]feeds.Attachment{
+		{URL: "https://instagram.com/p/pasta.jpg", Type: "image/jpeg", Width: 1080, Height: 1080, Alt: "A plate of fresh pasta"},
+	})
 
-	return items, nil
-}
+	feeds.ScoreEngagement(items, nil)
 
-// Helper function to return a pointer to a string
-func stringPtr(s string) *string {
-	return &s
+	return items, nil
 }
@@ -49,6 +49,11 @@ func TestInstagramFeed_Fetch(t *testing.T) {
 	if items[0].MediaURL == nil || *items[0].MediaURL != expectedMediaURL1 {
 		t.Errorf("Item 1 MediaURL: Expected %s, got %v", expectedMediaURL1, items[0].MediaURL)
 	}
+	if len(items[0].Media) != 2 {
+		t.Errorf("Item 1 Media: Expected 2 attachments, got %d", len(items[0].Media))
+	} else if items[0].Media[1].URL != "https://instagram.com/p/beach.jpg" || items[0].Media[0].Alt == "" {
+		t.Errorf("Item 1 Media: Unexpected attachments %+v", items[0].Media)
+	}
 	if items[0].ProfileLink != expectedProfileLink1 {
 		t.Errorf("Item 1 ProfileLink: Expected %s, got %s", expectedProfileLink1, items[0].ProfileLink)
 	}
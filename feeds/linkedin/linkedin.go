@@ -9,6 +9,9 @@ import (
 	"feed/feeds"
 )
 
+// source describes the platform for the sources table.
+var source = feeds.Source{ID: "linkedin", Platform: "linkedin", Title: "LinkedIn", HomeURL: "https://www.linkedin.com", IconURL: "https://www.linkedin.com/favicon.ico"}
+
 // LinkedInFeed implements the SocialFeed interface for LinkedIn.
 type LinkedInFeed struct{}
 
@@ -34,24 +37,34 @@ func (l *LinkedInFeed) Fetch() ([]feeds.FeedItem, error) {
 	// Simulate fetching a few items
 	items := []feeds.FeedItem{
 		{
-			Platform:     "linkedin",
-			PostContent:  "Excited to share my latest project! #golang #softwaredevelopment",
-			Username:     "Jane Doe",
-			MediaURL:     nil,
-			ProfileLink:  "https://linkedin.com/in/janedoe",
-			Timestamp:    time.Now().Add(-2 * time.Hour),
-			Interactions: 120,
+			Platform:    "linkedin",
+			Kind:        feeds.KindPost,
+			PostContent: "Excited to share my latest project! #golang #softwaredevelopment",
+			ContentText: "Excited to share my latest project! #golang #softwaredevelopment",
+			Username:    "Jane Doe",
+			MediaURL:    nil,
+			ProfileLink: "https://linkedin.com/in/janedoe",
+			Author:      &feeds.Author{Handle: "janedoe", DisplayName: "Jane Doe", ProfileURL: "https://linkedin.com/in/janedoe"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-2 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 95, Replies: 18, Shares: 7, Views: 4300},
 		},
 		{
-			Platform:     "linkedin",
-			PostContent:  "Great discussion on microservices architecture today.",
-			Username:     "John Smith",
-			MediaURL:     nil,
-			ProfileLink:  "https://linkedin.com/in/johnsmith",
-			Timestamp:    time.Now().Add(-24 * time.Hour),
-			Interactions: 85,
+			Platform:    "linkedin",
+			Kind:        feeds.KindPost,
+			PostContent: "Great discussion on microservices architecture today.",
+			ContentText: "Great discussion on microservices architecture today.",
+			Username:    "John Smith",
+			MediaURL:    nil,
+			ProfileLink: "https://linkedin.com/in/johnsmith",
+			Author:      &feeds.Author{Handle: "johnsmith", DisplayName: "John Smith", ProfileURL: "https://linkedin.com/in/johnsmith"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-24 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 70, Replies: 12, Shares: 3, Views: 2100},
 		},
 	}
 
+	feeds.ScoreEngagement(items, nil)
+
 	return items, nil
 }
@@ -0,0 +1,95 @@
+package feeds
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pagination determines how a feed is split into pages.
+type Pagination string
+
+const (
+	// PaginationNewest counts pages from the newest item: page 1 is the newest.
+	PaginationNewest Pagination = "newest"
+	// PaginationStable counts pages from the oldest item, so that only the
+	// newest page changes as items are added.
+	PaginationStable Pagination = "stable"
+)
+
+// ParsePagination validates a configured pagination mode. An empty value
+// selects PaginationNewest.
+func ParsePagination(value string) (Pagination, error) {
+	switch Pagination(strings.ToLower(strings.TrimSpace(value))) {
+	case "", PaginationNewest:
+		return PaginationNewest, nil
+	case PaginationStable:
+		return PaginationStable, nil
+	default:
+		return "", fmt.Errorf("unknown pagination mode %q (expected newest or stable)", value)
+	}
+}
+
+// Page is one page of a paginated feed.
+type Page struct {
+	Number int        // Starting at 1
+	Items  []FeedItem // Newest first
+	Newer  int        // Number of the page with the next newer items, or 0
+	Older  int        // Number of the page with the next older items, or 0
+}
+
+// Paginate splits items, sorted newest first, into pages of size items and
+// returns the pages newest first. A size below 1 puts every item on one
+// page; no items give no pages.
+//
+// With PaginationNewest page 1 holds the newest items, so every new item
+// moves items across all pages. With PaginationStable, pages are counted from the oldest item
+// instead: the newest page (the head) holds what is left over, and every
+// other page holds exactly size items. As new items arrive only the head
+// changes, until it is full and a new head is started, so older pages keep
+// their number and their items.
+func Paginate(items []FeedItem, size int, mode Pagination) []Page {
+	if len(items) == 0 {
+		return nil
+	}
+	if size < 1 || size > len(items) {
+		size = len(items)
+	}
+	total := (len(items) + size - 1) / size
+	stable := mode == PaginationStable
+
+	// head is the number of items on the newest page
+	head := size
+	if stable {
+		head = len(items) - (total-1)*size
+	}
+
+	pages := make([]Page, total)
+	start := 0
+	for i := range pages {
+		end := head + i*size
+		if end > len(items) {
+			end = len(items)
+		}
+		page := Page{Items: items[start:end]}
+		if stable {
+			page.Number = total - i
+			if i > 0 {
+				page.Newer = page.Number + 1
+			}
+			if i < total-1 {
+				page.Older = page.Number - 1
+			}
+		} else {
+			page.Number = i + 1
+			if i > 0 {
+				page.Newer = page.Number - 1
+			}
+			if i < total-1 {
+				page.Older = page.Number + 1
+			}
+		}
+		pages[i] = page
+		start = end
+	}
+	return pages
+}
@@ -0,0 +1,78 @@
+package feeds
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func numberedItems(n int) []FeedItem {
+	items := make([]FeedItem, n)
+	for i := range items {
+		items[i] = FeedItem{ID: fmt.Sprint(n - i)} // Newest first, the oldest is "1"
+	}
+	return items
+}
+
+// describe lists each page as "number:newer/older:ids".
+func describe(pages []Page) []string {
+	var result []string
+	for _, page := range pages {
+		ids := ""
+		for _, item := range page.Items {
+			ids += item.ID
+		}
+		result = append(result, fmt.Sprintf("%d:%d/%d:%s", page.Number, page.Newer, page.Older, ids))
+	}
+	return result
+}
+
+func TestPaginate(t *testing.T) {
+	expected := []string{"1:0/2:765", "2:1/3:432", "3:2/0:1"}
+	if got := describe(Paginate(numberedItems(7), 3, PaginationNewest)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	expected = []string{"1:0/0:321"}
+	if got := describe(Paginate(numberedItems(3), 0, PaginationNewest)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected one page without a size, got %v", got)
+	}
+
+	if pages := Paginate(nil, 3, PaginationNewest); pages != nil {
+		t.Errorf("Expected no pages for no items, got %v", describe(pages))
+	}
+}
+
+func TestPaginate_Stable(t *testing.T) {
+	expected := []string{"3:0/2:7", "2:3/1:654", "1:2/0:321"}
+	if got := describe(Paginate(numberedItems(7), 3, PaginationStable)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// New items only change the head until it is full
+	expected = []string{"3:0/2:87", "2:3/1:654", "1:2/0:321"}
+	if got := describe(Paginate(numberedItems(8), 3, PaginationStable)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	expected = []string{"4:0/3:10", "3:4/2:987", "2:3/1:654", "1:2/0:321"}
+	if got := describe(Paginate(numberedItems(10), 3, PaginationStable)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := map[string]Pagination{
+		"":         PaginationNewest,
+		"newest":   PaginationNewest,
+		" Stable ": PaginationStable,
+	}
+	for value, expected := range tests {
+		mode, err := ParsePagination(value)
+		if err != nil || mode != expected {
+			t.Errorf("ParsePagination(%q): Expected %q, got %q (%v)", value, expected, mode, err)
+		}
+	}
+	if _, err := ParsePagination("cursor"); err == nil {
+		t.Errorf("Expected an error for an unknown mode")
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"feed/feeds"
 )
 
+// source describes the platform for the sources table.
+var source = feeds.Source{ID: "reddit", Platform: "reddit", Title: "Reddit", HomeURL: "https://www.reddit.com", IconURL: "https://www.reddit.com/favicon.ico"}
+
 // RedditFeed implements the SocialFeed interface for Reddit.
 type RedditFeed struct{}
 
@@ -36,24 +39,31 @@ func (r *RedditFeed) Fetch() ([]feeds.FeedItem, error) {
 	// Simulate fetching a few items
 	items := []feeds.FeedItem{
 		{
-			Platform:     "reddit",
-			PostContent:  "Check out this interesting discussion on r/golang!",
-			Username:     "GoLover",
-			MediaURL:     nil,
-			ProfileLink:  "https://reddit.com/user/GoLover",
-			Timestamp:    time.Now().Add(-6 * time.Hour),
-			Interactions: 500,
+			Platform:    "reddit",
+			Kind:        feeds.KindPost,
+			PostContent: "Check out this interesting discussion on r/golang!",
+			ContentText: "Check out this interesting discussion on r/golang!",
+			Username:    "GoLover",
+			ProfileLink: "https://reddit.com/user/GoLover",
+			Author:      &feeds.Author{Handle: "GoLover", DisplayName: "GoLover", ProfileURL: "https://reddit.com/user/GoLover"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-6 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 460, Replies: 40},
 		},
 		{
-			Platform:     "reddit",
-			PostContent:  "My thoughts on the latest tech news.",
-			Username:     "NewsReader",
-			MediaURL:     nil,
-			ProfileLink:  "https://reddit.com/user/NewsReader",
-			Timestamp:    time.Now().Add(-28 * time.Hour),
-			Interactions: 150,
+			Platform:    "reddit",
+			Kind:        feeds.KindPost,
+			PostContent: "My thoughts on the latest tech news.",
+			ContentText: "My thoughts on the latest tech news.",
+			Username:    "NewsReader",
+			ProfileLink: "https://reddit.com/user/NewsReader",
+			Author:      &feeds.Author{Handle: "NewsReader", DisplayName: "NewsReader", ProfileURL: "https://reddit.com/user/NewsReader"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-28 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 120, Replies: 30, Views: 2400},
 		},
 	}
+	feeds.ScoreEngagement(items, nil)
 
 	return items, nil
 }
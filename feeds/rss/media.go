@@ -0,0 +1,262 @@
+package rss
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"feed/feeds"
+)
+
+// Enclosure is the RSS 2.0 <enclosure> element.
+type Enclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// MediaContent is a Media RSS <media:content> element.
+type MediaContent struct {
+	URL         string           `xml:"url,attr"`
+	Type        string           `xml:"type,attr"`
+	FileSize    string           `xml:"fileSize,attr"`
+	Width       string           `xml:"width,attr"`
+	Height      string           `xml:"height,attr"`
+	Duration    string           `xml:"duration,attr"`
+	Medium      string           `xml:"medium,attr"` // image, audio, video, document or executable
+	Description string           `xml:"http://search.yahoo.com/mrss/ description"`
+	Thumbnails  []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// MediaThumbnail is a Media RSS <media:thumbnail> element.
+type MediaThumbnail struct {
+	URL    string `xml:"url,attr"`
+	Width  string `xml:"width,attr"`
+	Height string `xml:"height,attr"`
+}
+
+// MediaGroup is a Media RSS <media:group>, which wraps alternate renditions.
+type MediaGroup struct {
+	Contents   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	Thumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// ITunesImage is the podcast <itunes:image> element.
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// itemMedia collects an item's attachments in this order of precedence:
+// <enclosure>, <media:content> (including inside <media:group>),
+// <media:thumbnail>, <itunes:image>, then the <img> tags in
+// <content:encoded> or, failing that, <description>. Thumbnails and the
+// iTunes image become the poster of an audio or video attachment that lacks
+// one instead of separate images. Relative URLs are resolved against base and
+// duplicates are dropped.
+func itemMedia(item Item, base string) []feeds.Attachment {
+	c := &mediaCollector{base: base, seen: make(map[string]bool)}
+
+	for _, enclosure := range item.Enclosures {
+		c.add(feeds.Attachment{
+			URL:      enclosure.URL,
+			Type:     enclosure.Type,
+			Length:   parseLength(enclosure.Length),
+			Duration: parseDuration(item.ITunesDuration),
+		}, "")
+	}
+
+	groups := append([]MediaGroup{{Contents: item.MediaContents, Thumbnails: item.MediaThumbnails}}, item.MediaGroups...)
+	var posters []MediaThumbnail
+	for _, group := range groups {
+		for _, content := range group.Contents {
+			attachment := feeds.Attachment{
+				URL:      content.URL,
+				Type:     content.Type,
+				Length:   parseLength(content.FileSize),
+				Width:    parseInt(content.Width),
+				Height:   parseInt(content.Height),
+				Duration: parseDuration(content.Duration),
+				Alt:      strings.TrimSpace(content.Description),
+			}
+			if len(content.Thumbnails) > 0 {
+				attachment.Thumbnail = resolveURL(base, strings.TrimSpace(content.Thumbnails[0].URL))
+			}
+			c.add(attachment, content.Medium)
+		}
+		posters = append(posters, group.Thumbnails...)
+	}
+	if item.ITunesImage.Href != "" {
+		posters = append(posters, MediaThumbnail{URL: item.ITunesImage.Href})
+	}
+	for _, poster := range posters {
+		if !c.setPoster(poster.URL) {
+			c.add(feeds.Attachment{URL: poster.URL, Width: parseInt(poster.Width), Height: parseInt(poster.Height)}, "image")
+		}
+	}
+
+	for _, fragment := range []string{item.ContentEncoded, item.Description} {
+		images := inlineImages(fragment)
+		for _, image := range images {
+			c.add(image, "image")
+		}
+		if len(images) > 0 {
+			break
+		}
+	}
+
+	return c.media
+}
+
+// mediaCollector accumulates attachments, normalizing their URLs and types.
+type mediaCollector struct {
+	base  string
+	seen  map[string]bool
+	media []feeds.Attachment
+}
+
+// add appends attachment unless its URL was seen. medium is the kind of
+// media the source says it is (such as media:content's medium attribute),
+// or "" when unknown; it is consulted when the attachment has no type.
+func (c *mediaCollector) add(attachment feeds.Attachment, medium string) {
+	attachment.URL = resolveURL(c.base, strings.TrimSpace(attachment.URL))
+	if attachment.URL == "" || c.seen[attachment.URL] {
+		return
+	}
+	c.seen[attachment.URL] = true
+	if attachment.Type == "" {
+		attachment.Type = mediumType(medium, guessType(attachment.URL))
+	}
+	c.media = append(c.media, attachment)
+}
+
+// setPoster makes rawURL the thumbnail of the first audio or video
+// attachment without one, reporting whether there was such an attachment.
+func (c *mediaCollector) setPoster(rawURL string) bool {
+	rawURL = resolveURL(c.base, strings.TrimSpace(rawURL))
+	for i, attachment := range c.media {
+		if attachment.Thumbnail == "" && (strings.HasPrefix(attachment.Type, "audio/") || strings.HasPrefix(attachment.Type, "video/")) {
+			c.media[i].Thumbnail = rawURL
+			return true
+		}
+	}
+	return false
+}
+
+// inlineImages returns the <img> tags of an HTML fragment as attachments,
+// skipping inline data URIs and 1x1 tracking pixels.
+func inlineImages(fragment string) []feeds.Attachment {
+	var images []feeds.Attachment
+	tokenizer := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return images
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data != "img" {
+				continue
+			}
+			var image feeds.Attachment
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "src":
+					image.URL = attr.Val
+				case "alt":
+					image.Alt = strings.TrimSpace(attr.Val)
+				case "width":
+					image.Width = parseInt(attr.Val)
+				case "height":
+					image.Height = parseInt(attr.Val)
+				}
+			}
+			if image.URL == "" || strings.HasPrefix(image.URL, "data:") || (image.Width == 1 && image.Height == 1) {
+				continue
+			}
+			images = append(images, image)
+		}
+	}
+}
+
+func parseInt(value string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// parseDuration reads a duration in seconds ("1830", "1830.5") or clock form
+// ("30:30", "1:30:30") as used by <itunes:duration>.
+func parseDuration(value string) float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	var seconds float64
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds
+}
+
+func parseLength(value string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// resolveURL makes ref absolute relative to base, returning ref unchanged if
+// either cannot be parsed.
+func resolveURL(base, ref string) string {
+	refURL, err := url.Parse(ref)
+	if err != nil || refURL.IsAbs() {
+		return ref
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil || !baseURL.IsAbs() {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+// mediumType returns the type for an attachment of the given medium: guessed,
+// the type inferred from the file extension, if it is of that medium, or
+// else a wildcard such as "image/*", so that an image without an extension
+// is still known to be one. Mediums that are not MIME top-level types, such
+// as "document", only keep guessed.
+func mediumType(medium, guessed string) string {
+	medium = strings.ToLower(strings.TrimSpace(medium))
+	switch medium {
+	case "image", "audio", "video":
+		if strings.HasPrefix(guessed, medium+"/") {
+			return guessed
+		}
+		return medium + "/*"
+	}
+	return guessed
+}
+
+// guessType infers a MIME type from the URL's file extension.
+func guessType(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	mediaType := mime.TypeByExtension(strings.ToLower(path.Ext(u.Path)))
+	if mediaType == "" {
+		return ""
+	}
+	// Drop parameters such as "; charset=utf-8"
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return mediaType
+}
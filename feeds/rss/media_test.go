@@ -0,0 +1,76 @@
+package rss
+
+import (
+	"testing"
+
+	"feed/feeds"
+)
+
+func TestItemMedia_VideoPosters(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     Item
+		expected []feeds.Attachment
+		mediaURL string
+	}{
+		{
+			name: "thumbnail",
+			item: Item{
+				Enclosures:      []Enclosure{{URL: "clip.mp4", Type: "video/mp4"}},
+				MediaThumbnails: []MediaThumbnail{{URL: "poster.jpg"}},
+			},
+			expected: []feeds.Attachment{{URL: "http://example.com/posts/clip.mp4", Type: "video/mp4", Thumbnail: "http://example.com/posts/poster.jpg"}},
+			mediaURL: "http://example.com/posts/poster.jpg",
+		},
+		{
+			// The content's own thumbnail wins; the item's becomes an image
+			name: "own thumbnail",
+			item: Item{
+				MediaContents:   []MediaContent{{URL: "clip.mp4", Medium: "video", Thumbnails: []MediaThumbnail{{URL: "frame.jpg"}}}},
+				MediaThumbnails: []MediaThumbnail{{URL: "cover.jpg"}},
+			},
+			expected: []feeds.Attachment{
+				{URL: "http://example.com/posts/clip.mp4", Type: "video/mp4", Thumbnail: "http://example.com/posts/frame.jpg"},
+				{URL: "http://example.com/posts/cover.jpg", Type: "image/jpeg"},
+			},
+			mediaURL: "http://example.com/posts/cover.jpg",
+		},
+		{
+			name: "itunes image",
+			item: Item{
+				Enclosures:  []Enclosure{{URL: "https://cdn.example.com/show.m4v", Type: "video/x-m4v"}},
+				ITunesImage: ITunesImage{Href: "https://cdn.example.com/art.png"},
+			},
+			expected: []feeds.Attachment{{URL: "https://cdn.example.com/show.m4v", Type: "video/x-m4v", Thumbnail: "https://cdn.example.com/art.png"}},
+			mediaURL: "https://cdn.example.com/art.png",
+		},
+		{
+			name:     "no poster",
+			item:     Item{Enclosures: []Enclosure{{URL: "clip.mp4", Type: "video/mp4"}}},
+			expected: []feeds.Attachment{{URL: "http://example.com/posts/clip.mp4", Type: "video/mp4"}},
+		},
+	}
+
+	for _, tt := range tests {
+		media := itemMedia(tt.item, "http://example.com/posts/")
+		if len(media) != len(tt.expected) {
+			t.Errorf("%s: Expected %d attachments, got %+v", tt.name, len(tt.expected), media)
+			continue
+		}
+		for i, expected := range tt.expected {
+			if media[i] != expected {
+				t.Errorf("%s: Expected %+v, got %+v", tt.name, expected, media[i])
+			}
+		}
+
+		var item feeds.FeedItem
+		item.SetMedia(media)
+		got := ""
+		if item.MediaURL != nil {
+			got = *item.MediaURL
+		}
+		if got != tt.mediaURL {
+			t.Errorf("%s: Expected MediaURL %q, got %q", tt.name, tt.mediaURL, got)
+		}
+	}
+}
@@ -1,101 +1,313 @@
 package rss
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
+	"golang.org/x/net/html/charset"
+
 	"feed/feeds"
+	"feed/httpcache"
 )
 
+// rdfNamespace is the namespace of the <rdf:RDF> root used by RSS 1.0 feeds.
+const rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
 // RSSFeed implements the SocialFeed interface for RSS.
 type RSSFeed struct {
 	URL string
+	// DateFallback decides what happens to items whose date cannot be parsed.
+	DateFallback feeds.DateFallback
+	// Client performs the request; share one with a cache directory to enable
+	// conditional GETs across runs.
+	Client *httpcache.Client
+	// Name is the display name for the source, used as the author of items
+	// that name none. Defaults to the channel title.
+	Name string
+	// Tags are attached to every item from this source.
+	Tags []string
+	// Platform labels the items, e.g. "blog" or "podcast". Defaults to "rss".
+	Platform string
+	// Author, if set, replaces the username of every item.
+	Author string
+	// Limit caps the number of (newest) items returned; 0 means no limit.
+	Limit int
+	// Header is sent with the request, e.g. for authentication.
+	Header http.Header
 }
 
 // NewRSSFeed creates a new RSSFeed instance.
 func NewRSSFeed(url string) *RSSFeed {
-	return &RSSFeed{URL: url}
+	return &RSSFeed{URL: url, DateFallback: feeds.DateFallbackKeep, Client: httpcache.New(""), Platform: "rss"}
 }
 
 // Fetch retrieves RSS feed items.
 func (r *RSSFeed) Fetch() ([]feeds.FeedItem, error) {
 	log.Printf("Fetching RSS feed from: %s", r.URL)
 
-	resp, err := http.Get(r.URL)
+	channel, err := r.fetchChannel()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch RSS feed from %s: %w", r.URL, err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch RSS feed from %s, status code: %d", r.URL, resp.StatusCode)
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read RSS feed response body from %s: %w", r.URL, err)
-	}
-
-	var rssData RSS
-	err = xml.Unmarshal(body, &rssData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal RSS feed from %s: %w", r.URL, err)
-	}
+	source := r.source(channel)
 
 	var items []feeds.FeedItem
-	for _, item := range rssData.Channel.Items {
-		t, err := time.Parse(time.RFC1123Z, item.PubDate) // Common RSS date format
+	for _, item := range channel.Items {
+		// RSS 1.0 feeds carry their date in Dublin Core instead of pubDate
+		date := item.PubDate
+		if date == "" {
+			date = item.DCDate
+		}
+		t, err := feeds.ParseTimestamp(date)
 		if err != nil {
-			// Try other common formats if RFC1123Z fails
-			t, err = time.Parse(time.RFC3339, item.PubDate)
-			if err != nil {
-				log.Printf("Warning: Could not parse date '%s' for RSS item from %s: %v", item.PubDate, r.URL, err)
-				t = time.Now() // Default to current time if parsing fails
+			log.Printf("Warning: Could not parse date '%s' for RSS item '%s' from %s: %v (date fallback: %s)", date, item.Title, r.URL, err, r.DateFallback)
+			switch r.DateFallback {
+			case feeds.DateFallbackDrop:
+				continue
+			case feeds.DateFallbackChannel:
+				t = channelTimestamp(channel)
+			default:
+				t = time.Time{}
 			}
 		}
 
-		// Use channel title as username if item author is not available
+		// Use the Dublin Core creator, then the source name or channel title, if item author is not available
 		username := item.Author
 		if username == "" {
-			username = rssData.Channel.Title
+			username = item.DCCreator
+		}
+		if username == "" {
+			username = r.Name
+		}
+		if username == "" {
+			username = channel.Title
+		}
+		if r.Author != "" {
+			username = r.Author
 		}
 
 		// Use link as profile link
 		profileLink := item.Link
 		if profileLink == "" {
-			profileLink = rssData.Channel.Link
+			profileLink = channel.Link
 		}
 
-		items = append(items, feeds.FeedItem{
-			Platform:     "rss",
+		// content:encoded carries the full article; description is then just its summary
+		body, summary := item.Description, ""
+		if item.ContentEncoded != "" {
+			body, summary = item.ContentEncoded, feeds.HTMLToText(item.Description)
+		}
+
+		language := item.Language
+		if language == "" {
+			language = channel.Language
+		}
+
+		feedItem := feeds.FeedItem{
+			ID:           itemID(r.Platform, item),
+			Platform:     r.Platform,
+			Kind:         feeds.KindArticle,
+			Title:        strings.TrimSpace(item.Title),
 			PostContent:  item.Title + "\n" + item.Description, // Combine title and description
+			ContentText:  feeds.HTMLToText(body),
+			Summary:      summary,
+			Language:     strings.TrimSpace(language),
 			Username:     username,
-			MediaURL:     nil, // RSS typically doesn't have a direct media_url field like social media
+			Author:       &feeds.Author{DisplayName: username, ProfileURL: channel.Link},
+			Source:       &source,
 			ProfileLink:  profileLink,
 			Timestamp:    t,
 			Interactions: 0, // RSS feeds typically don't have interaction counts
+			Tags:         feeds.AddTags(feeds.AddTags(append([]string(nil), r.Tags...), item.Categories...), item.DCSubjects...),
+		}
+		if strings.Contains(body, "<") {
+			feedItem.ContentHTML = body
+		}
+		feedItem.SetMedia(itemMedia(item, profileLink))
+		if len(feedItem.Media) > 0 && strings.HasPrefix(feedItem.Media[0].Type, "video/") {
+			feedItem.Kind = feeds.KindVideo
+		}
+		items = append(items, feedItem)
+	}
+
+	if r.Limit > 0 && len(items) > r.Limit {
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Timestamp.After(items[j].Timestamp)
 		})
+		items = items[:r.Limit]
 	}
 
 	return items, nil
 }
 
+// fetchChannel downloads and parses the feed, or reuses the channel parsed on
+// an earlier run when the server reports it unchanged. The parsed channel is
+// what gets cached, so per-source settings always apply to fresh values.
+func (r *RSSFeed) fetchChannel() (*Channel, error) {
+	resp, err := r.Client.Get(r.URL, r.Header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch RSS feed from %s: %w", r.URL, err)
+	}
+
+	if resp.Cached {
+		var channel Channel
+		if err = resp.Decode(&channel); err == nil {
+			log.Printf("RSS feed %s not modified; reusing %d cached items.", r.URL, len(channel.Items))
+			return &channel, nil
+		}
+		log.Printf("Warning: Could not decode cached RSS feed for %s, fetching it again: %v", r.URL, err)
+		if resp, err = r.Client.Refresh(r.URL, r.Header); err != nil {
+			return nil, fmt.Errorf("failed to fetch RSS feed from %s: %w", r.URL, err)
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch RSS feed from %s, status code: %d", r.URL, resp.StatusCode)
+	}
+
+	channel, err := parseFeed(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal RSS feed from %s: %w", r.URL, err)
+	}
+
+	if err := r.Client.Store(resp, channel); err != nil {
+		log.Printf("Warning: Could not cache RSS feed from %s: %v", r.URL, err)
+	}
+
+	return channel, nil
+}
+
+// parseFeed decodes an RSS 0.9x/2.0 document (<rss> root) or an RSS 1.0 /
+// RSS 0.90 document (<rdf:RDF> root) and returns its channel. RDF feeds list
+// their items as siblings of the channel, so they are moved into it here.
+func parseFeed(body []byte) (*Channel, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	// Older feeds are frequently served as ISO-8859-1, Shift_JIS, EUC-JP, etc.
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	root, err := rootElement(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case root.Name.Local == "rss":
+		var rssData RSS
+		if err := decoder.DecodeElement(&rssData, &root); err != nil {
+			return nil, err
+		}
+		return &rssData.Channel, nil
+	case root.Name.Local == "RDF" && root.Name.Space == rdfNamespace:
+		var rdfData RDF
+		if err := decoder.DecodeElement(&rdfData, &root); err != nil {
+			return nil, err
+		}
+		channel := rdfData.Channel
+		channel.Items = append(channel.Items, rdfData.Items...)
+		return &channel, nil
+	default:
+		return nil, fmt.Errorf("unsupported root element <%s>", root.Name.Local)
+	}
+}
+
+// itemID identifies an item by its <guid>, falling back to its link.
+func itemID(platform string, item Item) string {
+	key := strings.TrimSpace(item.GUID)
+	if key == "" {
+		key = strings.TrimSpace(item.Link)
+	}
+	if key == "" {
+		return ""
+	}
+	return platform + ":" + key
+}
+
+// source describes the feed for the sources table.
+func (r *RSSFeed) source(channel *Channel) feeds.Source {
+	title := r.Name
+	if title == "" {
+		title = strings.TrimSpace(channel.Title)
+	}
+	icon := strings.TrimSpace(channel.Image.URL)
+	if icon == "" {
+		icon = strings.TrimSpace(channel.ITunesImage.Href)
+	}
+	return feeds.Source{
+		ID:       r.Platform + ":" + r.URL,
+		Platform: r.Platform,
+		Title:    title,
+		HomeURL:  strings.TrimSpace(channel.Link),
+		FeedURL:  r.URL,
+		IconURL:  icon,
+	}
+}
+
+// channelTimestamp returns the channel's own last-updated date, or the zero
+// time if the channel does not carry a parseable one.
+func channelTimestamp(channel *Channel) time.Time {
+	for _, date := range []string{channel.LastBuildDate, channel.PubDate, channel.DCDate} {
+		if t, err := feeds.ParseTimestamp(date); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// rootElement advances the decoder to the document's root element.
+func rootElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return xml.StartElement{}, fmt.Errorf("no root element found")
+		}
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
 // RSS structure for XML unmarshalling
 type RSS struct {
 	XMLName xml.Name `xml:"rss"`
 	Channel Channel  `xml:"channel"`
 }
 
+// RDF is the root of an RSS 1.0 (or Netscape RSS 0.90) document.
+type RDF struct {
+	XMLName xml.Name `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# RDF"`
+	Channel Channel  `xml:"channel"`
+	Items   []Item   `xml:"item"`
+}
+
 // Channel represents the RSS channel.
 type Channel struct {
-	XMLName xml.Name `xml:"channel"`
-	Title   string   `xml:"title"`
-	Link    string   `xml:"link"`
-	Items   []Item   `xml:"item"`
+	XMLName       xml.Name `xml:"channel"`
+	Title         string   `xml:"title"`
+	Link          string   `xml:"link"`
+	LastBuildDate string   `xml:"lastBuildDate"`
+	PubDate       string   `xml:"pubDate"`
+	DCDate        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Language      string   `xml:"language"` // Also matches dc:language in RSS 1.0
+	// ITunesImage must precede Image, which would otherwise also match <itunes:image>
+	ITunesImage ITunesImage  `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Image       ChannelImage `xml:"image"`
+	Items       []Item       `xml:"item"`
+}
+
+// ChannelImage is the channel's <image> logo.
+type ChannelImage struct {
+	URL string `xml:"url"`
 }
 
 // Item represents an individual RSS feed item.
@@ -103,7 +315,21 @@ type Item struct {
 	XMLName     xml.Name `xml:"item"`
 	Title       string   `xml:"title"`
 	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
 	Description string   `xml:"description"`
 	PubDate     string   `xml:"pubDate"`
 	Author      string   `xml:"author"`
+	DCDate      string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	DCCreator   string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Language    string   `xml:"http://purl.org/dc/elements/1.1/ language"`
+	Categories  []string `xml:"category"`
+	DCSubjects  []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+
+	ContentEncoded  string           `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Enclosures      []Enclosure      `xml:"enclosure"`
+	MediaContents   []MediaContent   `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnails []MediaThumbnail `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+	MediaGroups     []MediaGroup     `xml:"http://search.yahoo.com/mrss/ group"`
+	ITunesImage     ITunesImage      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	ITunesDuration  string           `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
 }
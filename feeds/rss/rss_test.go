@@ -3,11 +3,13 @@ package rss
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings" // Added for strings.Contains
 	"testing"
 	"time"
 
 	"feed/feeds"
+	"feed/httpcache"
 )
 
 func init() {
@@ -25,6 +27,7 @@ func TestRSSFeed_Fetch(t *testing.T) {
     <item>
       <title>First Post</title>
       <link>http://testblog.com/first</link>
+      <guid isPermaLink="false">first-post-guid</guid>
       <description>This is the first post content.</description>
       <pubDate>Mon, 01 Jan 2025 12:00:00 +0000</pubDate>
       <author>Author One</author>
@@ -72,6 +75,12 @@ func TestRSSFeed_Fetch(t *testing.T) {
 	if items[0].Platform != expectedPlatform1 {
 		t.Errorf("Item 1 Platform: Expected %s, got %s", expectedPlatform1, items[0].Platform)
 	}
+	if items[0].ID != "rss:first-post-guid" {
+		t.Errorf("Item 1 ID: Expected rss:first-post-guid, got %s", items[0].ID)
+	}
+	if items[1].ID != "rss:http://testblog.com/second" {
+		t.Errorf("Item 2 ID: Expected the link when there is no guid, got %s", items[1].ID)
+	}
 	if items[0].PostContent != expectedPostContent1 {
 		t.Errorf("Item 1 PostContent: Expected %s, got %s", expectedPostContent1, items[0].PostContent)
 	}
@@ -123,6 +132,645 @@ func TestRSSFeed_Fetch(t *testing.T) {
 	}
 }
 
+func TestRSSFeed_Fetch_RDF(t *testing.T) {
+	// Mock RSS 1.0 (RDF) feed content with Dublin Core fields
+	mockRDFContent := `<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF
+  xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+  xmlns:dc="http://purl.org/dc/elements/1.1/"
+  xmlns="http://purl.org/rss/1.0/">
+  <channel rdf:about="http://testlab.example.org/">
+    <title>Test Lab</title>
+    <link>http://testlab.example.org/</link>
+    <items>
+      <rdf:Seq>
+        <rdf:li rdf:resource="http://testlab.example.org/paper"/>
+        <rdf:li rdf:resource="http://testlab.example.org/notes"/>
+      </rdf:Seq>
+    </items>
+  </channel>
+  <item rdf:about="http://testlab.example.org/paper">
+    <title>New Paper</title>
+    <link>http://testlab.example.org/paper</link>
+    <description>Our paper was accepted.</description>
+    <dc:date>2025-01-02T09:30:00+09:00</dc:date>
+    <dc:creator>Researcher One</dc:creator>
+  </item>
+  <item rdf:about="http://testlab.example.org/notes">
+    <title>Lab Notes</title>
+    <link>http://testlab.example.org/notes</link>
+    <description>Weekly notes.</description>
+    <dc:date>2024-12-30T18:00:00Z</dc:date>
+  </item>
+</rdf:RDF>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rdf+xml")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(mockRDFContent))
+		if err != nil {
+			t.Fatalf("Failed to write mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	rssFeed := NewRSSFeed(server.URL)
+	items, err := rssFeed.Fetch()
+
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	expectedPostContent1 := "New Paper\nOur paper was accepted."
+	expectedUsername1 := "Researcher One"
+	expectedTimestamp1, _ := time.Parse(time.RFC3339, "2025-01-02T09:30:00+09:00")
+
+	if items[0].PostContent != expectedPostContent1 {
+		t.Errorf("Item 1 PostContent: Expected %s, got %s", expectedPostContent1, items[0].PostContent)
+	}
+	if items[0].Username != expectedUsername1 {
+		t.Errorf("Item 1 Username: Expected %s, got %s", expectedUsername1, items[0].Username)
+	}
+	if !items[0].Timestamp.Equal(expectedTimestamp1) {
+		t.Errorf("Item 1 Timestamp: Expected %v, got %v", expectedTimestamp1, items[0].Timestamp)
+	}
+
+	expectedUsername2 := "Test Lab" // Should fall back to channel title
+	expectedTimestamp2, _ := time.Parse(time.RFC3339, "2024-12-30T18:00:00Z")
+
+	if items[1].Username != expectedUsername2 {
+		t.Errorf("Item 2 Username: Expected %s, got %s", expectedUsername2, items[1].Username)
+	}
+	if !items[1].Timestamp.Equal(expectedTimestamp2) {
+		t.Errorf("Item 2 Timestamp: Expected %v, got %v", expectedTimestamp2, items[1].Timestamp)
+	}
+}
+
+func TestRSSFeed_Fetch_RSS091Latin1(t *testing.T) {
+	// RSS 0.91 feeds are often served as ISO-8859-1; "Caf\xe9" is "Café" in Latin-1.
+	mockRSSContent := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+		"<rss version=\"0.91\"><channel><title>Caf\xe9 News</title><link>http://cafe.example.com</link>" +
+		"<item><title>Menu</title><link>http://cafe.example.com/menu</link><description>Cr\xe8me br\xfbl\xe9e</description></item>" +
+		"</channel></rss>"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(mockRSSContent))
+		if err != nil {
+			t.Fatalf("Failed to write mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	rssFeed := NewRSSFeed(server.URL)
+	items, err := rssFeed.Fetch()
+
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+
+	expectedPostContent := "Menu\nCrème brûlée"
+	expectedUsername := "Café News"
+
+	if items[0].PostContent != expectedPostContent {
+		t.Errorf("PostContent: Expected %s, got %s", expectedPostContent, items[0].PostContent)
+	}
+	if items[0].Username != expectedUsername {
+		t.Errorf("Username: Expected %s, got %s", expectedUsername, items[0].Username)
+	}
+}
+
+func TestRSSFeed_Fetch_DateFallback(t *testing.T) {
+	mockRSSContent := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Blog</title>
+    <link>http://testblog.com</link>
+    <lastBuildDate>Tue, 07 Jan 2025 08:00:00 GMT</lastBuildDate>
+    <item>
+      <title>Dated Post</title>
+      <pubDate>Mon, 06 Jan 2025 12:00:00 EST</pubDate>
+    </item>
+    <item>
+      <title>Undated Post</title>
+      <pubDate>sometime last week</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(mockRSSContent))
+		if err != nil {
+			t.Fatalf("Failed to write mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	expectedDated := time.Date(2025, 1, 6, 17, 0, 0, 0, time.UTC)
+	expectedChannel := time.Date(2025, 1, 7, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		policy            feeds.DateFallback
+		expectedItems     int
+		expectedTimestamp time.Time
+	}{
+		{feeds.DateFallbackKeep, 2, time.Time{}},
+		{feeds.DateFallbackDrop, 1, time.Time{}},
+		{feeds.DateFallbackChannel, 2, expectedChannel},
+	}
+
+	for _, tt := range tests {
+		rssFeed := NewRSSFeed(server.URL)
+		rssFeed.DateFallback = tt.policy
+		items, err := rssFeed.Fetch()
+		if err != nil {
+			t.Fatalf("Fetch with policy %s returned an error: %v", tt.policy, err)
+		}
+
+		if len(items) != tt.expectedItems {
+			t.Fatalf("Policy %s: Expected %d items, got %d", tt.policy, tt.expectedItems, len(items))
+		}
+		if !items[0].Timestamp.Equal(expectedDated) {
+			t.Errorf("Policy %s: Expected dated item at %v, got %v", tt.policy, expectedDated, items[0].Timestamp)
+		}
+		if len(items) > 1 && !items[1].Timestamp.Equal(tt.expectedTimestamp) {
+			t.Errorf("Policy %s: Expected undated item at %v, got %v", tt.policy, tt.expectedTimestamp, items[1].Timestamp)
+		}
+	}
+}
+
+func TestRSSFeed_Fetch_Media(t *testing.T) {
+	mockRSSContent := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"
+  xmlns:media="http://search.yahoo.com/mrss/"
+  xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"
+  xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Test Blog</title>
+    <link>http://testblog.com</link>
+    <item>
+      <title>Episode</title>
+      <link>http://testblog.com/episode</link>
+      <enclosure url="http://testblog.com/episode.mp3" type="audio/mpeg" length="123456"/>
+      <itunes:duration>1:02:03</itunes:duration>
+      <media:thumbnail url="http://testblog.com/episode-thumb.jpg"/>
+    </item>
+    <item>
+      <title>Gallery</title>
+      <link>http://testblog.com/gallery</link>
+      <media:group>
+        <media:content url="http://testblog.com/gallery.png" fileSize="2048"/>
+      </media:group>
+      <itunes:image href="http://testblog.com/cover.jpg"/>
+    </item>
+    <item>
+      <title>Thumbnail</title>
+      <link>http://testblog.com/thumbnail</link>
+      <media:thumbnail url="http://testblog.com/thumb.jpg"/>
+    </item>
+    <item>
+      <title>Show</title>
+      <link>http://testblog.com/show</link>
+      <itunes:image href="http://testblog.com/show.jpg"/>
+    </item>
+    <item>
+      <title>Article</title>
+      <link>http://testblog.com/posts/article</link>
+      <content:encoded><![CDATA[<p>Intro</p><img alt="hero" src="/images/hero.webp?w=800&amp;h=600"><img src="second.jpg" width="640" height="480"><img src="/pixel.gif" width="1" height="1">]]></content:encoded>
+    </item>
+    <item>
+      <title>Photo</title>
+      <link>http://testblog.com/photo</link>
+      <media:content url="http://cdn.testblog.com/photo/12345" medium="image"/>
+      <media:content url="http://cdn.testblog.com/clip.mp4" medium="video"/>
+    </item>
+    <item>
+      <title>Plain</title>
+      <link>http://testblog.com/plain</link>
+      <description>No media here.</description>
+    </item>
+  </channel>
+</rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(mockRSSContent))
+		if err != nil {
+			t.Fatalf("Failed to write mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	rssFeed := NewRSSFeed(server.URL)
+	items, err := rssFeed.Fetch()
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	tests := []struct {
+		expectedURL   string
+		expectedMedia []feeds.Attachment
+	}{
+		{"http://testblog.com/episode-thumb.jpg", []feeds.Attachment{
+			{URL: "http://testblog.com/episode.mp3", Type: "audio/mpeg", Length: 123456, Duration: 3723, Thumbnail: "http://testblog.com/episode-thumb.jpg"},
+		}},
+		{"http://testblog.com/gallery.png", []feeds.Attachment{
+			{URL: "http://testblog.com/gallery.png", Type: "image/png", Length: 2048},
+			{URL: "http://testblog.com/cover.jpg", Type: "image/jpeg"},
+		}},
+		{"http://testblog.com/thumb.jpg", []feeds.Attachment{
+			{URL: "http://testblog.com/thumb.jpg", Type: "image/jpeg"},
+		}},
+		{"http://testblog.com/show.jpg", []feeds.Attachment{
+			{URL: "http://testblog.com/show.jpg", Type: "image/jpeg"},
+		}},
+		{"http://testblog.com/images/hero.webp?w=800&h=600", []feeds.Attachment{
+			{URL: "http://testblog.com/images/hero.webp?w=800&h=600", Type: "image/webp", Alt: "hero"},
+			{URL: "http://testblog.com/posts/second.jpg", Type: "image/jpeg", Width: 640, Height: 480},
+		}},
+		// Without an extension, the medium still marks the image as one
+		{"http://cdn.testblog.com/photo/12345", []feeds.Attachment{
+			{URL: "http://cdn.testblog.com/photo/12345", Type: "image/*"},
+			{URL: "http://cdn.testblog.com/clip.mp4", Type: "video/mp4"},
+		}},
+	}
+
+	if len(items) != len(tests)+1 {
+		t.Fatalf("Expected %d items, got %d", len(tests)+1, len(items))
+	}
+
+	for i, tt := range tests {
+		if items[i].MediaURL == nil {
+			t.Errorf("Item %d MediaURL: Expected %s, got nil", i+1, tt.expectedURL)
+		} else if *items[i].MediaURL != tt.expectedURL {
+			t.Errorf("Item %d MediaURL: Expected %s, got %s", i+1, tt.expectedURL, *items[i].MediaURL)
+		}
+		if len(items[i].Media) != len(tt.expectedMedia) {
+			t.Errorf("Item %d Media: Expected %d attachments, got %+v", i+1, len(tt.expectedMedia), items[i].Media)
+			continue
+		}
+		for j, expected := range tt.expectedMedia {
+			if items[i].Media[j] != expected {
+				t.Errorf("Item %d Media[%d]: Expected %+v, got %+v", i+1, j, expected, items[i].Media[j])
+			}
+		}
+	}
+
+	if items[len(tests)].MediaURL != nil || items[len(tests)].Media != nil {
+		t.Errorf("Item %d: Expected no media, got %v", len(tests)+1, items[len(tests)].Media)
+	}
+}
+
+func TestRSSFeed_Fetch_ExcerptHTMLDescription(t *testing.T) {
+	mockRSSContent := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Blog</title>
+    <item>
+      <title>Links</title>
+      <link>http://testblog.com/links</link>
+      <description>&lt;p&gt;Read &lt;a href="https://example.com/a/long/path"&gt;this post&lt;/a&gt; &amp;amp; more&lt;/p&gt;</description>
+    </item>
+  </channel>
+</rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(mockRSSContent))
+	}))
+	defer server.Close()
+
+	items, err := NewRSSFeed(server.URL).Fetch()
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+	if len(items) != 1 || !strings.Contains(items[0].PostContent, `<a href="https://example.com`) {
+		t.Fatalf("Expected post_content to hold the description's HTML, got %v", items)
+	}
+
+	// A limit of 20 falls inside the <a> tag of the raw description
+	excerpt := items[0].Excerpt(20)
+	if strings.ContainsAny(excerpt.PostContent, "<>") || strings.Contains(excerpt.PostContent, "&amp;") {
+		t.Errorf("Expected post_content to be shortened as plain text, got %q", excerpt.PostContent)
+	}
+	if excerpt.PostContent != "Links\nRead this…" || !excerpt.Truncated {
+		t.Errorf("Expected %q, got %q (truncated=%v)", "Links\nRead this…", excerpt.PostContent, excerpt.Truncated)
+	}
+}
+
+func TestRSSFeed_Fetch_NotModified(t *testing.T) {
+	mockRSSContent := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Blog</title>
+    <link>http://testblog.com</link>
+    <item>
+      <title>First Post</title>
+      <link>http://testblog.com/first</link>
+      <pubDate>Mon, 01 Jan 2025 12:00:00 +0000</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+	fullResponses := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullResponses++
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(mockRSSContent))
+		if err != nil {
+			t.Fatalf("Failed to write mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := httpcache.New(t.TempDir())
+	for run := 1; run <= 2; run++ {
+		rssFeed := NewRSSFeed(server.URL)
+		rssFeed.Client = client
+		items, err := rssFeed.Fetch()
+		if err != nil {
+			t.Fatalf("Run %d: Fetch returned an error: %v", run, err)
+		}
+		if len(items) != 1 || items[0].PostContent != "First Post\n" {
+			t.Errorf("Run %d: Expected the single cached item, got %v", run, items)
+		}
+	}
+
+	if fullResponses != 1 {
+		t.Errorf("Expected 1 full response, got %d", fullResponses)
+	}
+}
+
+func TestRSSFeed_Fetch_UndecodableCache(t *testing.T) {
+	mockRSSContent := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Blog</title>
+    <item>
+      <title>First Post</title>
+      <link>http://testblog.com/first</link>
+    </item>
+  </channel>
+</rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(mockRSSContent))
+	}))
+	defer server.Close()
+
+	// Seed the cache with a value that does not decode into a channel
+	client := httpcache.New(t.TempDir())
+	resp, err := client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if err := client.Store(resp, []string{"not", "a", "channel"}); err != nil {
+		t.Fatalf("Store returned an error: %v", err)
+	}
+
+	rssFeed := NewRSSFeed(server.URL)
+	rssFeed.Client = client
+	items, err := rssFeed.Fetch()
+	if err != nil {
+		t.Fatalf("Expected the feed to be fetched again, got error: %v", err)
+	}
+	if len(items) != 1 || items[0].PostContent != "First Post\n" {
+		t.Errorf("Expected the item from a fresh response, got %v", items)
+	}
+}
+
+func TestRSSFeed_Fetch_CachedPreview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		w.Write([]byte(`<rss version="2.0"><channel><title>Test Blog</title><item><title>First Post</title></item></channel></rss>`))
+	}))
+	defer server.Close()
+
+	// A link preview of the feed's URL, stored through the same client
+	client := httpcache.New(t.TempDir())
+	resp, err := client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if err := client.Store(resp, feeds.LinkPreview{URL: server.URL, Title: "Test Blog"}); err != nil {
+		t.Fatalf("Store returned an error: %v", err)
+	}
+
+	rssFeed := NewRSSFeed(server.URL)
+	rssFeed.Client = client
+	items, err := rssFeed.Fetch()
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+	if len(items) != 1 {
+		t.Errorf("Expected the preview not to be taken for the channel, got %d items", len(items))
+	}
+}
+
+func TestRSSFeed_Fetch_SourceSettings(t *testing.T) {
+	mockRSSContent := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Test Podcast</title>
+    <link>http://testpodcast.com</link>
+    <image><url>http://testpodcast.com/logo.png</url></image>
+    <itunes:image href="http://testpodcast.com/cover.jpg"/>
+    <item>
+      <title>Episode 1</title>
+      <pubDate>Mon, 01 Jan 2025 12:00:00 +0000</pubDate>
+      <author>Guest One</author>
+    </item>
+    <item>
+      <title>Episode 3</title>
+      <pubDate>Wed, 15 Jan 2025 12:00:00 +0000</pubDate>
+      <category>Audio</category>
+      <category domain="https://testpodcast.com/topics">Interviews</category>
+      <dc:subject>Go</dc:subject>
+    </item>
+    <item>
+      <title>Episode 2</title>
+      <pubDate>Wed, 08 Jan 2025 12:00:00 +0000</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(mockRSSContent))
+		if err != nil {
+			t.Fatalf("Failed to write mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	rssFeed := NewRSSFeed(server.URL)
+	rssFeed.Platform = "podcast"
+	rssFeed.Author = "Host"
+	rssFeed.Tags = []string{"audio"}
+	rssFeed.Limit = 2
+	rssFeed.Header = http.Header{"Authorization": {"Bearer token123"}}
+	items, err := rssFeed.Fetch()
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items after limit, got %d", len(items))
+	}
+	expectedContents := []string{"Episode 3\n", "Episode 2\n"} // The newest items are kept
+	for i, item := range items {
+		if item.PostContent != expectedContents[i] {
+			t.Errorf("Item %d PostContent: Expected %q, got %q", i+1, expectedContents[i], item.PostContent)
+		}
+		if item.Platform != "podcast" {
+			t.Errorf("Item %d Platform: Expected podcast, got %s", i+1, item.Platform)
+		}
+		if item.Username != "Host" {
+			t.Errorf("Item %d Username: Expected Host, got %s", i+1, item.Username)
+		}
+		if item.Author == nil || item.Author.DisplayName != "Host" || item.Author.ProfileURL != "http://testpodcast.com" {
+			t.Errorf("Item %d Author: Expected Host at http://testpodcast.com, got %+v", i+1, item.Author)
+		}
+		expectedSource := feeds.Source{
+			ID:       "podcast:" + server.URL,
+			Platform: "podcast",
+			Title:    "Test Podcast",
+			HomeURL:  "http://testpodcast.com",
+			FeedURL:  server.URL,
+			IconURL:  "http://testpodcast.com/logo.png",
+		}
+		if item.Source == nil || *item.Source != expectedSource {
+			t.Errorf("Item %d Source: Expected %+v, got %+v", i+1, expectedSource, item.Source)
+		}
+		// Categories join the source's tags, without case-insensitive duplicates
+		expectedTags := [][]string{{"audio", "Interviews", "Go"}, {"audio"}}[i]
+		if !reflect.DeepEqual(item.Tags, expectedTags) {
+			t.Errorf("Item %d Tags: Expected %v, got %v", i+1, expectedTags, item.Tags)
+		}
+	}
+
+	// Without an author override, the source name replaces the channel title
+	rssFeed = NewRSSFeed(server.URL)
+	rssFeed.Name = "My Podcast"
+	rssFeed.Header = http.Header{"Authorization": {"Bearer token123"}}
+	items, err = rssFeed.Fetch()
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+	if items[0].Username != "Guest One" {
+		t.Errorf("Item 1 Username: Expected Guest One, got %s", items[0].Username)
+	}
+	if items[1].Username != "My Podcast" {
+		t.Errorf("Item 2 Username: Expected My Podcast, got %s", items[1].Username)
+	}
+	if items[0].Platform != "rss" {
+		t.Errorf("Item 1 Platform: Expected rss, got %s", items[0].Platform)
+	}
+}
+
+func TestRSSFeed_Fetch_ContentFields(t *testing.T) {
+	mockRSSContent := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Test Blog</title>
+    <link>http://testblog.com</link>
+    <language>en-us</language>
+    <item>
+      <title> Full Article </title>
+      <link>http://testblog.com/full</link>
+      <description>A &lt;b&gt;short&lt;/b&gt; teaser.</description>
+      <content:encoded><![CDATA[<p>Full &amp; complete</p><p>article body.</p>]]></content:encoded>
+    </item>
+    <item>
+      <title>Plain Post</title>
+      <link>http://testblog.com/plain</link>
+      <description>Just text.</description>
+    </item>
+  </channel>
+</rss>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(mockRSSContent))
+		if err != nil {
+			t.Fatalf("Failed to write mock response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	items, err := NewRSSFeed(server.URL).Fetch()
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	full := items[0]
+	if full.Kind != feeds.KindArticle {
+		t.Errorf("Item 1 Kind: Expected %s, got %s", feeds.KindArticle, full.Kind)
+	}
+	if full.Title != "Full Article" {
+		t.Errorf("Item 1 Title: Expected 'Full Article', got '%s'", full.Title)
+	}
+	if full.ContentHTML != "<p>Full &amp; complete</p><p>article body.</p>" {
+		t.Errorf("Item 1 ContentHTML: got '%s'", full.ContentHTML)
+	}
+	if full.ContentText != "Full & complete\narticle body." {
+		t.Errorf("Item 1 ContentText: got %q", full.ContentText)
+	}
+	if full.Summary != "A short teaser." {
+		t.Errorf("Item 1 Summary: Expected 'A short teaser.', got '%s'", full.Summary)
+	}
+	if full.Language != "en-us" {
+		t.Errorf("Item 1 Language: Expected 'en-us', got '%s'", full.Language)
+	}
+
+	plain := items[1]
+	if plain.ContentText != "Just text." {
+		t.Errorf("Item 2 ContentText: Expected 'Just text.', got '%s'", plain.ContentText)
+	}
+	if plain.ContentHTML != "" {
+		t.Errorf("Item 2 ContentHTML: Expected empty, got '%s'", plain.ContentHTML)
+	}
+	if plain.Summary != "" {
+		t.Errorf("Item 2 Summary: Expected empty, got '%s'", plain.Summary)
+	}
+}
+
 func TestRSSFeed_Fetch_InvalidURL(t *testing.T) {
 	rssFeed := NewRSSFeed("http://invalid-url-that-does-not-exist.com")
 	_, err := rssFeed.Fetch()
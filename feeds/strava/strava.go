@@ -7,6 +7,9 @@ import (
 	"feed/feeds"
 )
 
+// source describes the platform for the sources table.
+var source = feeds.Source{ID: "strava", Platform: "strava", Title: "Strava", HomeURL: "https://www.strava.com", IconURL: "https://www.strava.com/favicon.ico"}
+
 // StravaFeed implements the SocialFeed interface for Strava.
 type StravaFeed struct {
 	// Add any Strava-specific configuration here, e.g., API client
@@ -22,15 +25,23 @@ func (sf *StravaFeed) Fetch() ([]feeds.FeedItem, error) {
 	log.Println("Simulating Strava feed fetch...")
 	// In a real implementation, this would involve calling the Strava API.
 	// For now, return a dummy item or an empty slice.
-	return []feeds.FeedItem{
+	items := []feeds.FeedItem{
 		{
-			Platform:     "strava",
-			PostContent:  "Just completed a 10k run!",
-			Username:     "strava_user",
-			MediaURL:     nil,
-			ProfileLink:  "https://www.strava.com/athletes/strava_user",
-			Timestamp:    time.Now(),
-			Interactions: 15,
+			Platform:    "strava",
+			Kind:        feeds.KindActivity,
+			Title:       "Morning Run",
+			PostContent: "Just completed a 10k run!",
+			ContentText: "Just completed a 10k run!",
+			Username:    "strava_user",
+			MediaURL:    nil,
+			ProfileLink: "https://www.strava.com/athletes/strava_user",
+			Author:      &feeds.Author{Handle: "strava_user", DisplayName: "strava_user", ProfileURL: "https://www.strava.com/athletes/strava_user"},
+			Source:      &source,
+			Timestamp:   time.Now(),
+			Engagement:  &feeds.Engagement{Likes: 12, Replies: 3},
 		},
-	}, nil
+	}
+	feeds.ScoreEngagement(items, nil)
+
+	return items, nil
 }
@@ -3,6 +3,8 @@ package strava
 import (
 	"testing"
 	"time"
+
+	"feed/feeds"
 )
 
 func TestNewStravaFeed(t *testing.T) {
@@ -28,6 +30,9 @@ func TestStravaFeed_Fetch(t *testing.T) {
 		if item.Platform != "strava" {
 			t.Errorf("Expected platform 'strava', got '%s'", item.Platform)
 		}
+		if item.Kind != feeds.KindActivity {
+			t.Errorf("Expected kind '%s', got '%s'", feeds.KindActivity, item.Kind)
+		}
 		if item.PostContent == "" {
 			t.Error("PostContent is empty")
 		}
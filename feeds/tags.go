@@ -0,0 +1,91 @@
+package feeds
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Tag groups the items that carry a tag, whatever its capitalization.
+type Tag struct {
+	Name  string     // Spelling of the first item carrying the tag
+	Slug  string     // File name safe form; see TagSlug
+	Items []FeedItem // In the order given to GroupByTag
+}
+
+// AddTags appends each of more to tags unless a tag of the same name,
+// ignoring case, is already present.
+func AddTags(tags []string, more ...string) []string {
+	for _, tag := range more {
+		if tag = strings.TrimSpace(tag); tag == "" || containsTag(tags, tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, existing := range tags {
+		if strings.EqualFold(existing, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// TagSlug lower-cases tag and replaces every run of characters other than
+// letters and digits with a hyphen, e.g. "Go Programming" becomes
+// "go-programming". It returns "" for a tag without letters or digits.
+func TagSlug(tag string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(tag) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+// GroupByTag returns the tags of items, most used first and then by slug.
+// Tags with the same slug, such as "Go" and "go", are one tag.
+func GroupByTag(items []FeedItem) []Tag {
+	bySlug := make(map[string]*Tag)
+	var tags []*Tag
+	for _, item := range items {
+		seen := make(map[string]bool)
+		for _, name := range item.Tags {
+			slug := TagSlug(name)
+			if slug == "" || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			tag, ok := bySlug[slug]
+			if !ok {
+				tag = &Tag{Name: strings.TrimSpace(name), Slug: slug}
+				bySlug[slug] = tag
+				tags = append(tags, tag)
+			}
+			tag.Items = append(tag.Items, item)
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		if len(tags[i].Items) != len(tags[j].Items) {
+			return len(tags[i].Items) > len(tags[j].Items)
+		}
+		return tags[i].Slug < tags[j].Slug
+	})
+	grouped := make([]Tag, len(tags))
+	for i, tag := range tags {
+		grouped[i] = *tag
+	}
+	return grouped
+}
@@ -0,0 +1,62 @@
+package feeds
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddTags(t *testing.T) {
+	tags := AddTags([]string{"go"}, "Go", "  ", "rust", "RUST", "tech")
+
+	expected := []string{"go", "rust", "tech"}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Expected %v, got %v", expected, tags)
+	}
+}
+
+func TestTagSlug(t *testing.T) {
+	tests := map[string]string{
+		"golang":          "golang",
+		"Go Programming":  "go-programming",
+		"  C++ / Rust!  ": "c-rust",
+		"日本語":             "日本語",
+		"#!?":             "",
+	}
+	for tag, expected := range tests {
+		if slug := TagSlug(tag); slug != expected {
+			t.Errorf("TagSlug(%q): Expected %q, got %q", tag, expected, slug)
+		}
+	}
+}
+
+func TestGroupByTag(t *testing.T) {
+	items := []FeedItem{
+		{ID: "1", Tags: []string{"Go", "tech"}},
+		{ID: "2", Tags: []string{"go", "GO"}},
+		{ID: "3", Tags: []string{"tech", "rust", "???"}},
+		{ID: "4"},
+	}
+
+	tags := GroupByTag(items)
+
+	expected := []struct {
+		name, slug string
+		ids        []string
+	}{
+		{"Go", "go", []string{"1", "2"}},
+		{"tech", "tech", []string{"1", "3"}},
+		{"rust", "rust", []string{"3"}},
+	}
+	if len(tags) != len(expected) {
+		t.Fatalf("Expected %d tags, got %d", len(expected), len(tags))
+	}
+	for i, want := range expected {
+		var ids []string
+		for _, item := range tags[i].Items {
+			ids = append(ids, item.ID)
+		}
+		if tags[i].Name != want.name || tags[i].Slug != want.slug || !reflect.DeepEqual(ids, want.ids) {
+			t.Errorf("Tag %d: Expected %s (%s) with %v, got %s (%s) with %v", i+1, want.name, want.slug, want.ids, tags[i].Name, tags[i].Slug, ids)
+		}
+	}
+}
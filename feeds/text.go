@@ -0,0 +1,62 @@
+package feeds
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockElements end a line of text when converting HTML to plain text.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true, "figure": true,
+	"footer": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "tr": true, "ul": true,
+}
+
+// HTMLToText converts an HTML fragment to plain text: tags are dropped,
+// entities decoded, block elements become line breaks, and whitespace is
+// collapsed. Plain text passes through with only its whitespace tidied.
+func HTMLToText(s string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	var b strings.Builder
+	skip := 0 // depth inside <script>/<style>
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return tidyLines(b.String())
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(strings.ReplaceAll(string(tokenizer.Text()), "\n", " "))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			switch tag := string(name); {
+			case tag == "script" || tag == "style":
+				skip++
+			case blockElements[tag]:
+				b.WriteByte('\n')
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch tag := string(name); {
+			case (tag == "script" || tag == "style") && skip > 0:
+				skip--
+			case blockElements[tag]:
+				b.WriteByte('\n')
+			}
+		}
+	}
+}
+
+// tidyLines collapses whitespace within each line and drops empty lines.
+func tidyLines(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
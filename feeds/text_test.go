@@ -0,0 +1,24 @@
+package feeds
+
+import "testing"
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Plain   text\n with  spacing ", "Plain text with spacing"},
+		{"<p>First &amp; <b>bold</b></p><p>Second&nbsp;para</p>", "First & bold\nSecond para"},
+		{"Line one<br>Line two<br/>Line three", "Line one\nLine two\nLine three"},
+		{"<ul><li>One</li><li>Two</li></ul>", "One\nTwo"},
+		{"<p>Text</p><script>alert('x')</script><style>p{}</style>", "Text"},
+		{"Caf&eacute; &lt;tag&gt; &#8220;quoted&#8221;", "Café <tag> “quoted”"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := HTMLToText(tt.input); got != tt.expected {
+			t.Errorf("HTMLToText(%q): Expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package feeds
+
+import (
+	"sort"
+	"strings"
+)
+
+// FoldThreads merges every self-reply chain, a post followed by its author's
+// own replies to it, into a single KindThread item whose Parts hold the posts
+// in order. Replies to someone else, or to a post that is not in items, stay
+// as they are. The result keeps the order of items, with each thread at the
+// position of its first post.
+func FoldThreads(items []FeedItem) []FeedItem {
+	byID := make(map[string]int)
+	for i, item := range items {
+		if item.ID != "" {
+			byID[item.ID] = i
+		}
+	}
+
+	roots := make([]int, len(items))
+	replies := make(map[int][]int)
+	for i := range items {
+		roots[i] = threadRoot(items, byID, i)
+		if roots[i] != i {
+			replies[roots[i]] = append(replies[roots[i]], i)
+		}
+	}
+
+	folded := make([]FeedItem, 0, len(items))
+	for i, item := range items {
+		switch {
+		case roots[i] != i:
+			continue
+		case len(replies[i]) == 0:
+			folded = append(folded, item)
+		default:
+			folded = append(folded, foldThread(items, i, replies[i]))
+		}
+	}
+	return folded
+}
+
+// threadRoot follows i's chain of self-replies up to the first post. An item
+// caught in a reply cycle is its own root.
+func threadRoot(items []FeedItem, byID map[string]int, i int) int {
+	visited := map[int]bool{i: true}
+	current := i
+	for {
+		parent, ok := byID[items[current].InReplyTo]
+		if !ok || !sameAuthor(items[parent], items[current]) {
+			return current
+		}
+		if visited[parent] {
+			return i
+		}
+		visited[parent] = true
+		current = parent
+	}
+}
+
+func sameAuthor(a, b FeedItem) bool {
+	return a.Platform == b.Platform && strings.EqualFold(a.Username, b.Username)
+}
+
+// foldThread builds the thread item for the post at root and its replies. Its
+// content, media and engagement combine those of all parts.
+func foldThread(items []FeedItem, root int, replies []int) FeedItem {
+	parts := []FeedItem{items[root]}
+	for _, i := range replies {
+		parts = append(parts, items[i])
+	}
+	sort.SliceStable(parts[1:], func(i, j int) bool {
+		return parts[1+i].Timestamp.Before(parts[1+j].Timestamp)
+	})
+
+	thread := items[root]
+	thread.Kind = KindThread
+	if thread.ThreadID == "" {
+		thread.ThreadID = thread.ID
+	}
+
+	var postContent, contentText, contentHTML []string
+	var media []Attachment
+	var engagement *Engagement
+	thread.Interactions = 0
+	for i := range parts {
+		parts[i].ThreadID = thread.ThreadID
+		part := parts[i]
+		postContent = append(postContent, part.PostContent)
+		if part.ContentText != "" {
+			contentText = append(contentText, part.ContentText)
+		}
+		if part.ContentHTML != "" {
+			contentHTML = append(contentHTML, part.ContentHTML)
+		}
+		media = append(media, part.Media...)
+		thread.Interactions += part.Interactions
+		if part.Engagement != nil {
+			if engagement == nil {
+				engagement = &Engagement{}
+			}
+			engagement.add(*part.Engagement)
+		}
+	}
+	thread.PostContent = strings.Join(postContent, "\n\n")
+	thread.ContentText = strings.Join(contentText, "\n\n")
+	thread.ContentHTML = strings.Join(contentHTML, "\n")
+	thread.SetMedia(media)
+	thread.Engagement = engagement
+	thread.Parts = parts
+	return thread
+}
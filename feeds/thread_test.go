@@ -0,0 +1,63 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFoldThreads(t *testing.T) {
+	start := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	items := []FeedItem{
+		{ID: "x:4", Platform: "x", Username: "GoDev", PostContent: "3/3", ContentText: "3/3", InReplyTo: "x:2", Timestamp: start.Add(3 * time.Minute), Interactions: 1},
+		{ID: "x:3", Platform: "x", Username: "Other", PostContent: "Nice thread", InReplyTo: "x:1", Timestamp: start.Add(2 * time.Minute), Interactions: 5},
+		{ID: "x:2", Platform: "x", Username: "GoDev", PostContent: "2/3", ContentText: "2/3", InReplyTo: "x:1", Timestamp: start.Add(time.Minute), Interactions: 2, Engagement: &Engagement{Likes: 2}},
+		{ID: "x:1", Platform: "x", Username: "GoDev", Kind: KindPost, PostContent: "1/3", ContentText: "1/3", Timestamp: start, Interactions: 3, Engagement: &Engagement{Likes: 3}},
+		{ID: "x:9", Platform: "x", Username: "GoDev", PostContent: "Reply to a missing post", InReplyTo: "x:8", Timestamp: start},
+	}
+
+	folded := FoldThreads(items)
+
+	if len(folded) != 3 {
+		t.Fatalf("Expected 3 items after folding, got %d", len(folded))
+	}
+	if folded[0].ID != "x:3" || folded[2].ID != "x:9" {
+		t.Errorf("Expected unrelated replies to keep their place, got %s and %s", folded[0].ID, folded[2].ID)
+	}
+
+	thread := folded[1]
+	if thread.ID != "x:1" || thread.Kind != KindThread || thread.ThreadID != "x:1" {
+		t.Errorf("Expected thread x:1 of kind thread, got %s (%s, thread_id %s)", thread.ID, thread.Kind, thread.ThreadID)
+	}
+	expectedParts := []string{"x:1", "x:2", "x:4"}
+	if len(thread.Parts) != len(expectedParts) {
+		t.Fatalf("Expected %d parts, got %d", len(expectedParts), len(thread.Parts))
+	}
+	for i, id := range expectedParts {
+		if thread.Parts[i].ID != id {
+			t.Errorf("Part %d: Expected %s, got %s", i+1, id, thread.Parts[i].ID)
+		}
+		if thread.Parts[i].ThreadID != "x:1" {
+			t.Errorf("Part %d ThreadID: Expected x:1, got %s", i+1, thread.Parts[i].ThreadID)
+		}
+	}
+	if thread.ContentText != "1/3\n\n2/3\n\n3/3" {
+		t.Errorf("Expected combined content, got %q", thread.ContentText)
+	}
+	if thread.Interactions != 6 {
+		t.Errorf("Expected interactions 6, got %d", thread.Interactions)
+	}
+	if thread.Engagement == nil || thread.Engagement.Likes != 5 {
+		t.Errorf("Expected 5 combined likes, got %+v", thread.Engagement)
+	}
+}
+
+func TestFoldThreads_Cycle(t *testing.T) {
+	items := []FeedItem{
+		{ID: "x:1", Platform: "x", Username: "GoDev", InReplyTo: "x:2"},
+		{ID: "x:2", Platform: "x", Username: "GoDev", InReplyTo: "x:1"},
+	}
+
+	if folded := FoldThreads(items); len(folded) != 2 {
+		t.Errorf("Expected items in a reply cycle to be kept as-is, got %d items", len(folded))
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"feed/feeds"
 )
 
+// source describes the platform for the sources table.
+var source = feeds.Source{ID: "threads", Platform: "threads", Title: "Threads", HomeURL: "https://www.threads.net", IconURL: "https://www.threads.net/favicon.ico"}
+
 // ThreadsFeed implements the SocialFeed interface for Threads.
 type ThreadsFeed struct{}
 
@@ -34,24 +37,37 @@ func (t *ThreadsFeed) Fetch() ([]feeds.FeedItem, error) {
 	// Simulate fetching a few items
 	items := []feeds.FeedItem{
 		{
-			Platform:     "threads",
-			PostContent:  "Just posted on Threads! Loving the new features.",
-			Username:     "ThreadsUser1",
-			MediaURL:     nil,
-			ProfileLink:  "https://threads.net/threadsuser1",
-			Timestamp:    time.Now().Add(-1 * time.Hour),
-			Interactions: 50,
+			ID:          "threads:3001",
+			Platform:    "threads",
+			Kind:        feeds.KindPost,
+			PostContent: "Just posted on Threads! Loving the new features.",
+			ContentText: "Just posted on Threads! Loving the new features.",
+			Username:    "ThreadsUser1",
+			MediaURL:    nil,
+			ProfileLink: "https://threads.net/threadsuser1",
+			Author:      &feeds.Author{Handle: "threadsuser1", DisplayName: "ThreadsUser1", ProfileURL: "https://threads.net/threadsuser1"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-1 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 41, Replies: 6, Shares: 3},
+			QuotedItem:  "threads:3002",
 		},
 		{
-			Platform:     "threads",
-			PostContent:  "Discussing the future of decentralized social media.",
-			Username:     "DecentralGuru",
-			MediaURL:     nil,
-			ProfileLink:  "https://threads.net/decentralguru",
-			Timestamp:    time.Now().Add(-12 * time.Hour),
-			Interactions: 30,
+			ID:          "threads:3002",
+			Platform:    "threads",
+			Kind:        feeds.KindPost,
+			PostContent: "Discussing the future of decentralized social media.",
+			ContentText: "Discussing the future of decentralized social media.",
+			Username:    "DecentralGuru",
+			MediaURL:    nil,
+			ProfileLink: "https://threads.net/decentralguru",
+			Author:      &feeds.Author{Handle: "decentralguru", DisplayName: "DecentralGuru", ProfileURL: "https://threads.net/decentralguru"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-12 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 25, Replies: 4, Shares: 1},
 		},
 	}
 
+	feeds.ScoreEngagement(items, nil)
+
 	return items, nil
 }
@@ -0,0 +1,202 @@
+package feeds
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFallback determines what happens to an item whose date cannot be parsed.
+type DateFallback string
+
+const (
+	// DateFallbackKeep keeps the item with a zero timestamp, so it sorts last.
+	// The zero time is still written to JSON, as "0001-01-01T00:00:00Z".
+	DateFallbackKeep DateFallback = "keep"
+	// DateFallbackDrop removes the item from the feed.
+	DateFallbackDrop DateFallback = "drop"
+	// DateFallbackChannel uses the feed's own last-updated date (e.g. lastBuildDate).
+	DateFallbackChannel DateFallback = "channel"
+)
+
+// ParseDateFallback validates a configured fallback policy. An empty value
+// selects DateFallbackKeep.
+func ParseDateFallback(value string) (DateFallback, error) {
+	switch DateFallback(strings.ToLower(strings.TrimSpace(value))) {
+	case "", DateFallbackKeep:
+		return DateFallbackKeep, nil
+	case DateFallbackDrop:
+		return DateFallbackDrop, nil
+	case DateFallbackChannel:
+		return DateFallbackChannel, nil
+	default:
+		return "", fmt.Errorf("unknown date fallback policy %q (expected keep, drop or channel)", value)
+	}
+}
+
+// zoneOffsets maps the zone abbreviations seen in RFC 822 dates to their UTC
+// offsets in minutes. time.Parse only resolves abbreviations known to the local
+// zone and silently treats the rest as UTC, so they are substituted up front.
+var zoneOffsets = map[string]int{
+	"UT": 0, "UTC": 0, "GMT": 0, "Z": 0, "WET": 0,
+	"EST": -5 * 60, "EDT": -4 * 60,
+	"CST": -6 * 60, "CDT": -5 * 60,
+	"MST": -7 * 60, "MDT": -6 * 60,
+	"PST": -8 * 60, "PDT": -7 * 60,
+	"AKST": -9 * 60, "AKDT": -8 * 60,
+	"HST": -10 * 60,
+	"BST": 1 * 60, "CET": 1 * 60, "WEST": 1 * 60,
+	"CEST": 2 * 60, "EET": 2 * 60,
+	"EEST": 3 * 60, "MSK": 3 * 60,
+	"IST": 5*60 + 30,
+	"SGT": 8 * 60, "HKT": 8 * 60, "AWST": 8 * 60,
+	"JST": 9 * 60, "KST": 9 * 60,
+	"AEST": 10 * 60, "AEDT": 11 * 60,
+	"NZST": 12 * 60, "NZDT": 13 * 60,
+}
+
+// rfc822Layouts cover RFC 822/1123 dates once the weekday has been stripped
+// and any named zone replaced with a numeric offset.
+var rfc822Layouts = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 06 15:04:05",
+	"2 Jan 2006",
+	"2-Jan-06 15:04:05 -0700", // RFC 850, e.g. "Monday, 02-Jan-06 15:04:05 MST"
+	"2-Jan-2006 15:04:05 -0700",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2, 2006 15:04:05 -0700",
+	"January 2, 2006",
+}
+
+// iso8601Layouts cover RFC 3339, Dublin Core dc:date (W3C-DTF) and the looser
+// ISO 8601 forms found in the wild. Values without a zone are taken as UTC.
+var iso8601Layouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"20060102T150405Z0700",
+	"20060102T150405",
+	"2006-01-02",
+	"2006-01",
+	"20060102",
+}
+
+// ParseTimestamp parses the date formats commonly found in feeds: RFC 822/1123
+// with numeric or named zones, two-digit years, missing seconds or weekday,
+// and a trailing comment such as "(UTC)"; RFC 850; ISO 8601 and RFC 3339
+// variants, including Dublin Core dc:date; and Unix epochs in seconds or
+// milliseconds.
+func ParseTimestamp(value string) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	for _, layout := range iso8601Layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	if t, ok := parseEpoch(value); ok {
+		return t, nil
+	}
+
+	normalized := normalizeRFC822(value)
+	for _, layout := range rfc822Layouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date format %q", value)
+}
+
+// parseEpoch interprets an all-digit value as Unix seconds, or milliseconds
+// when it is too long to be a plausible number of seconds.
+func parseEpoch(value string) (time.Time, bool) {
+	if len(value) < 9 || len(value) > 13 {
+		return time.Time{}, false
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return time.Time{}, false
+	}
+	if len(value) > 11 {
+		return time.UnixMilli(n).UTC(), true
+	}
+	return time.Unix(n, 0).UTC(), true
+}
+
+// normalizeRFC822 strips a leading weekday and a trailing comment, and
+// rewrites a trailing zone abbreviation as a numeric offset, e.g.
+// "Mon, 02 Jan 2006 15:04 EST" becomes "02 Jan 2006 15:04 -0500".
+func normalizeRFC822(value string) string {
+	fields := strings.Fields(strings.ReplaceAll(stripComment(value), ",", ", "))
+	if len(fields) > 0 && isWeekday(strings.TrimSuffix(fields[0], ",")) {
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		last := len(fields) - 1
+		if offset, ok := zoneOffsets[strings.ToUpper(fields[last])]; ok {
+			fields[last] = formatOffset(offset)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// stripComment removes a trailing RFC 822 comment, as in
+// "Mon, 02 Jan 2006 15:04:05 +0000 (UTC)". When no zone comes before the
+// comment, as in "02 Jan 2006 15:04:05 (EST)", the comment is taken as the
+// zone.
+func stripComment(value string) string {
+	open := strings.LastIndexByte(value, '(')
+	if open <= 0 || !strings.HasSuffix(value, ")") {
+		return value
+	}
+	rest := strings.TrimSpace(value[:open])
+	fields := strings.Fields(rest)
+	last := fields[len(fields)-1]
+	if _, named := zoneOffsets[strings.ToUpper(last)]; named || strings.HasPrefix(last, "+") || strings.HasPrefix(last, "-") {
+		return rest
+	}
+	return rest + " " + value[open+1:len(value)-1]
+}
+
+func isWeekday(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+	for _, day := range []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"} {
+		if strings.HasPrefix(day, strings.ToLower(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatOffset(minutes int) string {
+	sign := '+'
+	if minutes < 0 {
+		sign = '-'
+		minutes = -minutes
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, minutes/60, minutes%60)
+}
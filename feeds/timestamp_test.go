@@ -0,0 +1,89 @@
+package feeds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	est := time.FixedZone("", -5*60*60)
+	jst := time.FixedZone("", 9*60*60)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		// RFC 822 / RFC 1123 variants
+		{"Mon, 01 Jan 2025 12:00:00 +0000", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"Wed, 01 Jan 2025 12:00:00 GMT", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"Wed, 01 Jan 2025 07:00:00 EST", time.Date(2025, 1, 1, 7, 0, 0, 0, est)},
+		{"Wed, 1 Jan 2025 21:00:00 JST", time.Date(2025, 1, 1, 21, 0, 0, 0, jst)},
+		{"Wed, 01 Jan 25 12:00:00 +0000", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"Wed, 01 Jan 2025 12:00 +0000", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"01 Jan 2025 12:00:00 UT", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"Wednesday, 01 January 2025 12:00:00 +0000", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"  Wed,  01 Jan 2025\n12:00:00 +0000 ", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		// Trailing comments
+		{"Wed, 01 Jan 2025 12:00:00 +0000 (UTC)", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"Wed, 01 Jan 2025 07:00:00 -0500 (Eastern Standard Time)", time.Date(2025, 1, 1, 7, 0, 0, 0, est)},
+		{"Wed, 01 Jan 2025 07:00:00 EST (EST)", time.Date(2025, 1, 1, 7, 0, 0, 0, est)},
+		{"Wed, 01 Jan 2025 07:00:00 (EST)", time.Date(2025, 1, 1, 7, 0, 0, 0, est)},
+		// RFC 850
+		{"Wednesday, 01-Jan-25 12:00:00 GMT", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"Wednesday, 01-Jan-25 07:00:00 EST", time.Date(2025, 1, 1, 7, 0, 0, 0, est)},
+		{"Wednesday, 1-Jan-2025 21:00:00 +0900", time.Date(2025, 1, 1, 21, 0, 0, 0, jst)},
+		// ISO 8601 / RFC 3339 / dc:date variants
+		{"2025-01-01T12:00:00Z", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"2025-01-01T21:00:00+09:00", time.Date(2025, 1, 1, 21, 0, 0, 0, jst)},
+		{"2025-01-01T21:00:00+0900", time.Date(2025, 1, 1, 21, 0, 0, 0, jst)},
+		{"2025-01-01T12:00:00.123Z", time.Date(2025, 1, 1, 12, 0, 0, 123000000, time.UTC)},
+		{"2025-01-01T21:00+09:00", time.Date(2025, 1, 1, 21, 0, 0, 0, jst)},
+		{"2025-01-01 12:00:00", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"2025-01-01", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"20250101T120000Z", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		// Unix epochs
+		{"1735732800", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"1735732800000", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.input)
+		if err != nil {
+			t.Errorf("ParseTimestamp(%q) returned an error: %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.expected) {
+			t.Errorf("ParseTimestamp(%q): Expected %v, got %v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestParseTimestamp_Invalid(t *testing.T) {
+	for _, input := range []string{"", "   ", "not a date", "yesterday at noon", "32 Foo 2025"} {
+		if got, err := ParseTimestamp(input); err == nil {
+			t.Errorf("ParseTimestamp(%q): Expected an error, got %v", input, got)
+		}
+	}
+}
+
+func TestParseDateFallback(t *testing.T) {
+	tests := map[string]DateFallback{
+		"":        DateFallbackKeep,
+		"keep":    DateFallbackKeep,
+		"DROP":    DateFallbackDrop,
+		"channel": DateFallbackChannel,
+	}
+	for input, expected := range tests {
+		got, err := ParseDateFallback(input)
+		if err != nil {
+			t.Errorf("ParseDateFallback(%q) returned an error: %v", input, err)
+		}
+		if got != expected {
+			t.Errorf("ParseDateFallback(%q): Expected %s, got %s", input, expected, got)
+		}
+	}
+
+	if _, err := ParseDateFallback("now"); err == nil {
+		t.Errorf("Expected an error for unknown policy, got nil")
+	}
+}
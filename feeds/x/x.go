@@ -9,6 +9,9 @@ import (
 	"feed/feeds"
 )
 
+// source describes the platform for the sources table.
+var source = feeds.Source{ID: "x", Platform: "x", Title: "X", HomeURL: "https://x.com", IconURL: "https://x.com/favicon.ico"}
+
 // XFeed implements the SocialFeed interface for X (formerly Twitter).
 type XFeed struct{}
 
@@ -34,24 +37,36 @@ func (x *XFeed) Fetch() ([]feeds.FeedItem, error) {
 	// Simulate fetching a few items
 	items := []feeds.FeedItem{
 		{
-			Platform:     "x",
-			PostContent:  "Just shared a new article on Go concurrency! #golang #programming",
-			Username:     "GoDev",
-			MediaURL:     nil,
-			ProfileLink:  "https://x.com/godev",
-			Timestamp:    time.Now().Add(-3 * time.Hour),
-			Interactions: 200,
+			ID:          "x:1001",
+			Platform:    "x",
+			Kind:        feeds.KindPost,
+			PostContent: "Just shared a new article on Go concurrency! #golang #programming",
+			ContentText: "Just shared a new article on Go concurrency! #golang #programming",
+			Username:    "GoDev",
+			MediaURL:    nil,
+			ProfileLink: "https://x.com/godev",
+			Author:      &feeds.Author{Handle: "godev", DisplayName: "GoDev", ProfileURL: "https://x.com/godev"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-3 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 150, Replies: 20, Shares: 25, Views: 12000, Bookmarks: 5},
 		},
 		{
-			Platform:     "x",
-			PostContent:  "Excited for the upcoming tech conference!",
-			Username:     "TechEnthusiast",
-			MediaURL:     nil,
-			ProfileLink:  "https://x.com/techenthusiast",
-			Timestamp:    time.Now().Add(-18 * time.Hour),
-			Interactions: 90,
+			ID:          "x:1002",
+			Platform:    "x",
+			Kind:        feeds.KindPost,
+			PostContent: "Excited for the upcoming tech conference!",
+			ContentText: "Excited for the upcoming tech conference!",
+			Username:    "TechEnthusiast",
+			MediaURL:    nil,
+			ProfileLink: "https://x.com/techenthusiast",
+			Author:      &feeds.Author{Handle: "techenthusiast", DisplayName: "TechEnthusiast", ProfileURL: "https://x.com/techenthusiast"},
+			Source:      &source,
+			Timestamp:   time.Now().Add(-18 * time.Hour),
+			Engagement:  &feeds.Engagement{Likes: 70, Replies: 10, Shares: 8, Views: 3400, Bookmarks: 2},
 		},
 	}
 
+	feeds.ScoreEngagement(items, nil)
+
 	return items, nil
 }
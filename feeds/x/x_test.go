@@ -58,6 +58,9 @@ func TestXFeed_Fetch(t *testing.T) {
 	if items[0].Interactions != expectedInteractions1 {
 		t.Errorf("Item 1 Interactions: Expected %d, got %d", expectedInteractions1, items[0].Interactions)
 	}
+	if items[0].Engagement == nil || items[0].Engagement.Shares != 25 || items[0].Engagement.Views != 12000 {
+		t.Errorf("Item 1 Engagement: Expected 25 shares and 12000 views, got %+v", items[0].Engagement)
+	}
 
 	// Test second item
 	expectedPlatform2 := "x"
@@ -89,6 +92,41 @@ func TestXFeed_Fetch(t *testing.T) {
 	}
 }
 
+func TestXFeed_Fetch_Thread(t *testing.T) {
+	t.Setenv("X_API_KEY", "dummy_key")
+	t.Setenv("X_API_SECRET", "dummy_secret")
+
+	items, err := NewXFeed().Fetch()
+	if err != nil {
+		t.Fatalf("Fetch returned an error: %v", err)
+	}
+
+	// A self-reply to the first post, as the API returns one
+	reply := feeds.FeedItem{
+		ID:          "x:1003",
+		Platform:    "x",
+		Kind:        feeds.KindReply,
+		PostContent: "Part two: channels vs. mutexes, and when to reach for each.",
+		Username:    "GoDev",
+		Timestamp:   items[0].Timestamp.Add(10 * time.Minute),
+		InReplyTo:   items[0].ID,
+	}
+	folded := feeds.FoldThreads(append(items, reply))
+
+	if len(folded) != 2 {
+		t.Fatalf("Expected 2 items after folding, got %d", len(folded))
+	}
+	if folded[0].Kind != feeds.KindThread || len(folded[0].Parts) != 2 {
+		t.Errorf("Item 1: Expected a thread of 2 parts, got %s with %d parts", folded[0].Kind, len(folded[0].Parts))
+	}
+	if folded[0].ThreadID != items[0].ID || folded[0].Parts[1].ID != reply.ID {
+		t.Errorf("Item 1: Expected thread %s ending with %s, got %+v", items[0].ID, reply.ID, folded[0])
+	}
+	if folded[1].ID != items[1].ID {
+		t.Errorf("Item 2: Expected %s to stay apart, got %s", items[1].ID, folded[1].ID)
+	}
+}
+
 func TestXFeed_Fetch_MissingAPIKeys(t *testing.T) {
 	// Unset environment variables to simulate missing keys
 	os.Unsetenv("X_API_KEY")
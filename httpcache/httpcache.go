@@ -0,0 +1,377 @@
+// Package httpcache provides the HTTP client shared by all outbound fetches.
+//
+// For every URL it remembers the ETag and Last-Modified validators in a cache
+// directory, along with the items parsed from the last full response. Later
+// requests are sent with If-None-Match/If-Modified-Since; when the server
+// answers 304 Not Modified, or the cached copy is still fresh according to
+// Cache-Control max-age, the stored items are handed back instead of
+// re-downloading and re-parsing the body. Entries are kept apart by the
+// client's Namespace and the request headers, since consumers store
+// different values for the same URL. Entries written in another format
+// version, or whose value is of another type or no longer decodes, are
+// ignored and the URL is fetched again unconditionally.
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds every request made through a Client.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultMaxBodySize bounds the body read from a response.
+const DefaultMaxBodySize = 32 << 20
+
+// FormatVersion is the version of the cache entries. It must be bumped
+// whenever the entry or the shape of a stored value changes, so entries from
+// older releases are re-fetched rather than decoded into the wrong shape.
+const FormatVersion = 2
+
+// Client performs cached, conditional GET requests.
+type Client struct {
+	// Dir is where cache entries are stored. Caching is disabled when empty,
+	// in which case the Client behaves like a plain http.Client.
+	Dir       string
+	HTTP      *http.Client
+	UserAgent string
+	// Namespace separates the entries of consumers that store different
+	// values, such as parsed feeds and link previews, for the same URL.
+	Namespace string
+	// MaxBodySize is the largest body accepted, in bytes; larger responses
+	// fail. DefaultMaxBodySize applies when zero.
+	MaxBodySize int64
+
+	now func() time.Time
+}
+
+// New creates a Client that stores its entries in dir.
+func New(dir string) *Client {
+	return &Client{
+		Dir:         dir,
+		HTTP:        &http.Client{Timeout: DefaultTimeout},
+		UserAgent:   "feed-generator",
+		MaxBodySize: DefaultMaxBodySize,
+		now:         time.Now,
+	}
+}
+
+// WithNamespace returns a copy of c whose entries are kept apart from those
+// of other namespaces.
+func (c *Client) WithNamespace(namespace string) *Client {
+	namespaced := *c
+	namespaced.Namespace = namespace
+	return &namespaced
+}
+
+// Response is the result of a Get.
+type Response struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Cached is true when no new body was downloaded, either because the
+	// cached entry was still fresh or because the server answered 304. The
+	// stored value is then available through Decode.
+	Cached bool
+
+	entry *entry
+	key   string // Path of the cache entry for the request
+}
+
+// Decode unmarshals the value stored for a cached response into v, which
+// must point to the type that was stored. Callers that get an error should
+// treat the entry as missing and Refresh the URL.
+func (r *Response) Decode(v any) error {
+	if r.entry == nil || len(r.entry.Value) == 0 {
+		return fmt.Errorf("no cached value for %s", r.URL)
+	}
+	if kind := valueType(v); kind != r.entry.Type {
+		return fmt.Errorf("cached value for %s is a %s, not a %s", r.URL, r.entry.Type, kind)
+	}
+	return json.Unmarshal(r.entry.Value, v)
+}
+
+// entry is the on-disk record for one URL.
+type entry struct {
+	Version      int             `json:"version"`
+	Namespace    string          `json:"namespace,omitempty"`
+	URL          string          `json:"url"`
+	Type         string          `json:"type"` // Go type of Value, see valueType
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	Expires      time.Time       `json:"expires,omitempty"`
+	StoredAt     time.Time       `json:"stored_at"`
+	Value        json.RawMessage `json:"value"`
+}
+
+// Get fetches url, sending any extra header values given. Non-2xx responses
+// are returned as-is for the caller to report; only transport failures are
+// returned as errors.
+func (c *Client) Get(url string, header http.Header) (*Response, error) {
+	cached := c.load(url, header)
+	if cached != nil && c.clock().Before(cached.Expires) {
+		return &Response{URL: url, StatusCode: http.StatusOK, Cached: true, entry: cached}, nil
+	}
+	return c.get(url, header, cached)
+}
+
+// Refresh fetches url unconditionally, ignoring any cache entry. It is used
+// when a cached value can no longer be decoded.
+func (c *Client) Refresh(url string, header http.Header) (*Response, error) {
+	return c.get(url, header, nil)
+}
+
+// get performs the request, made conditional on cached when it is set.
+func (c *Client) get(url string, header http.Header, cached *entry) (*Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if c.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	key := c.path(url, header)
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		// Servers may send refreshed validators or freshness with a 304
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			cached.ETag = etag
+		}
+		if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+			cached.LastModified = lastModified
+		}
+		if maxAge, ok := maxAge(resp.Header); ok {
+			cached.Expires = c.clock().Add(maxAge)
+		}
+		if err := c.save(key, cached); err != nil {
+			return nil, err
+		}
+		return &Response{URL: url, StatusCode: http.StatusOK, Header: resp.Header, Cached: true, entry: cached, key: key}, nil
+	}
+
+	limit := c.maxBodySize()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("response body from %s exceeds %d bytes", url, limit)
+	}
+	return &Response{URL: url, StatusCode: resp.StatusCode, Header: resp.Header, Body: body, key: key}, nil
+}
+
+// Store records resp's validators and freshness together with value, the
+// items parsed from its body, so that later requests can reuse them. It is a
+// no-op when caching is disabled, for cached responses, and for responses
+// that carry nothing to revalidate with or forbid storing.
+func (c *Client) Store(resp *Response, value any) error {
+	if c.Dir == "" || resp.Cached || resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	if noStore(resp.Header) {
+		return nil
+	}
+
+	e := &entry{
+		Version:      FormatVersion,
+		Namespace:    c.Namespace,
+		Type:         valueType(value),
+		URL:          resp.URL,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		StoredAt:     c.clock(),
+	}
+	maxAge, hasMaxAge := maxAge(resp.Header)
+	if hasMaxAge {
+		e.Expires = e.StoredAt.Add(maxAge)
+	}
+	if e.ETag == "" && e.LastModified == "" && !hasMaxAge {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value for %s: %w", resp.URL, err)
+	}
+	e.Value = data
+	return c.save(resp.key, e)
+}
+
+func (c *Client) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP != nil {
+		return c.HTTP
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) maxBodySize() int64 {
+	if c.MaxBodySize > 0 {
+		return c.MaxBodySize
+	}
+	return DefaultMaxBodySize
+}
+
+// path returns the file of the entry for url requested with header. The
+// key covers the namespace and the request headers, such as Accept and
+// Authorization, as they can change the response.
+func (c *Client) path(url string, header http.Header) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n", c.Namespace, url)
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, http.CanonicalHeaderKey(key))
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s: %s\n", key, strings.Join(header.Values(key), ", "))
+	}
+	return filepath.Join(c.Dir, hex.EncodeToString(hash.Sum(nil))+".json")
+}
+
+// valueType names the type of a stored value, seen through pointers, so that
+// Store(resp, channel) and Decode(&channel) agree.
+func valueType(v any) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	if t.Name() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
+
+// load returns the usable cache entry for url requested with header, or nil.
+// Entries of another format version or namespace are not usable.
+func (c *Client) load(url string, header http.Header) *entry {
+	if c.Dir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.path(url, header))
+	if err != nil {
+		return nil
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Version != FormatVersion || e.Namespace != c.Namespace || e.URL != url || len(e.Value) == 0 {
+		return nil
+	}
+	return &e
+}
+
+// save writes e to the file at key through a temporary file so an
+// interrupted run never leaves a truncated entry behind.
+func (c *Client) save(key string, e *entry) error {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", c.Dir, err)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), key)
+}
+
+// maxAge returns the freshness lifetime granted by Cache-Control, less any
+// time the response already spent in upstream caches (Age). no-cache counts
+// as a lifetime of zero.
+func maxAge(header http.Header) (time.Duration, bool) {
+	lifetime, found := time.Duration(0), false
+	for _, directive := range cacheControl(header) {
+		name, value, _ := strings.Cut(directive, "=")
+		switch name {
+		case "no-cache":
+			return 0, true
+		case "max-age":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil || seconds < 0 {
+				continue
+			}
+			lifetime, found = time.Duration(seconds)*time.Second, true
+		}
+	}
+	if !found {
+		return 0, false
+	}
+	if age, err := strconv.Atoi(header.Get("Age")); err == nil && age > 0 {
+		lifetime -= time.Duration(age) * time.Second
+	}
+	if lifetime < 0 {
+		lifetime = 0
+	}
+	return lifetime, true
+}
+
+func noStore(header http.Header) bool {
+	for _, directive := range cacheControl(header) {
+		if directive == "no-store" {
+			return true
+		}
+	}
+	return false
+}
+
+func cacheControl(header http.Header) []string {
+	var directives []string
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			if directive = strings.ToLower(strings.TrimSpace(directive)); directive != "" {
+				directives = append(directives, directive)
+			}
+		}
+	}
+	return directives
+}
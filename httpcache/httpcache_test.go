@@ -0,0 +1,336 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_ConditionalGet(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == "Mon, 06 Jan 2025 12:00:00 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 06 Jan 2025 12:00:00 GMT")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	client := New(t.TempDir())
+
+	resp, err := client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("First Get returned an error: %v", err)
+	}
+	if resp.Cached {
+		t.Errorf("Expected first response not to be cached")
+	}
+	if string(resp.Body) != "body" {
+		t.Errorf("Expected body 'body', got '%s'", resp.Body)
+	}
+	if err := client.Store(resp, []string{"parsed"}); err != nil {
+		t.Fatalf("Store returned an error: %v", err)
+	}
+
+	resp, err = client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Second Get returned an error: %v", err)
+	}
+	if !resp.Cached {
+		t.Fatalf("Expected second response to be served from cache after 304")
+	}
+	var value []string
+	if err := resp.Decode(&value); err != nil {
+		t.Fatalf("Decode returned an error: %v", err)
+	}
+	if len(value) != 1 || value[0] != "parsed" {
+		t.Errorf("Expected cached value [parsed], got %v", value)
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestClient_MaxAge(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Cache-Control", "public, max-age=600")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	client := New(t.TempDir())
+	client.now = func() time.Time { return now }
+
+	resp, err := client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if err := client.Store(resp, "parsed"); err != nil {
+		t.Fatalf("Store returned an error: %v", err)
+	}
+
+	now = now.Add(5 * time.Minute)
+	resp, err = client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if !resp.Cached {
+		t.Errorf("Expected fresh response to be served from cache")
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request while fresh, got %d", requests)
+	}
+
+	now = now.Add(10 * time.Minute)
+	resp, err = client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if resp.Cached {
+		t.Errorf("Expected stale response to be refetched")
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests after expiry, got %d", requests)
+	}
+}
+
+func TestClient_NoStore(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("Expected no conditional request for a no-store response")
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(t.TempDir())
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get returned an error: %v", err)
+		}
+		if err := client.Store(resp, "parsed"); err != nil {
+			t.Fatalf("Store returned an error: %v", err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestClient_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Test") != "yes" {
+			t.Errorf("Expected custom header to be sent")
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New("")
+	resp, err := client.Get(server.URL, http.Header{"X-Test": {"yes"}})
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if err := client.Store(resp, "parsed"); err != nil {
+		t.Errorf("Store with caching disabled returned an error: %v", err)
+	}
+	resp, err = client.Get(server.URL, http.Header{"X-Test": {"yes"}})
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if resp.Cached {
+		t.Errorf("Expected no cached responses when caching is disabled")
+	}
+}
+
+func TestClient_VersionMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("Expected an unconditional request for an entry of another version")
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	client := New(t.TempDir())
+	if err := client.save(client.path(server.URL, nil), &entry{Version: FormatVersion - 1, URL: server.URL, ETag: `"v1"`, Value: []byte(`"old"`)}); err != nil {
+		t.Fatalf("save returned an error: %v", err)
+	}
+	resp, err := client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if resp.Cached || string(resp.Body) != "body" {
+		t.Errorf("Expected the entry to be ignored, got cached=%v body '%s'", resp.Cached, resp.Body)
+	}
+}
+
+func TestClient_Refresh(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	client := New(t.TempDir())
+	resp, err := client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if err := client.Store(resp, "not a list"); err != nil {
+		t.Fatalf("Store returned an error: %v", err)
+	}
+
+	// The stored value no longer fits the caller's type, so it refreshes
+	resp, err = client.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	var value []string
+	if err := resp.Decode(&value); err == nil {
+		t.Fatalf("Expected Decode to fail, got %v", value)
+	}
+	resp, err = client.Refresh(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Refresh returned an error: %v", err)
+	}
+	if resp.Cached || string(resp.Body) != "body" {
+		t.Errorf("Expected a full response, got cached=%v body '%s'", resp.Cached, resp.Body)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
+
+func TestClient_MaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	client := New("")
+	client.MaxBodySize = 10
+	if _, err := client.Get(server.URL, nil); err != nil {
+		t.Errorf("Expected a body of exactly the limit to be read, got %v", err)
+	}
+	client.MaxBodySize = 9
+	if _, err := client.Get(server.URL, nil); err == nil {
+		t.Errorf("Expected an error for a body over the limit")
+	}
+}
+
+func TestClient_SeparateValues(t *testing.T) {
+	type channel struct{ Items []string }
+	type linkPreview struct{ URL, Title string }
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	rssClient, previewClient := New(dir).WithNamespace("rss"), New(dir).WithNamespace("preview")
+	for _, store := range []struct {
+		client *Client
+		value  any
+	}{
+		{rssClient, channel{Items: []string{"first"}}},
+		{previewClient, linkPreview{URL: server.URL, Title: "Page"}},
+	} {
+		resp, err := store.client.Get(server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get returned an error: %v", err)
+		}
+		if resp.Cached {
+			t.Errorf("Expected no entry shared between namespaces")
+		}
+		if err := store.client.Store(resp, store.value); err != nil {
+			t.Fatalf("Store returned an error: %v", err)
+		}
+	}
+
+	resp, err := rssClient.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	var c channel
+	if err := resp.Decode(&c); err != nil || len(c.Items) != 1 {
+		t.Errorf("Expected the stored channel, got %+v (%v)", c, err)
+	}
+
+	// Within one namespace, a value of another type is not decoded
+	resp, err = previewClient.Get(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if err := resp.Decode(&c); err == nil {
+		t.Errorf("Expected a preview not to decode as a channel")
+	}
+	var p linkPreview
+	if err := resp.Decode(&p); err != nil || p.Title != "Page" {
+		t.Errorf("Expected the stored preview, got %+v (%v)", p, err)
+	}
+}
+
+func TestClient_HeadersInKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"`+r.Header.Get("Authorization")+`"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(t.TempDir())
+	alice := http.Header{"Authorization": {"Bearer alice"}}
+	resp, err := client.Get(server.URL, alice)
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if err := client.Store(resp, "alice's feed"); err != nil {
+		t.Fatalf("Store returned an error: %v", err)
+	}
+
+	resp, err = client.Get(server.URL, http.Header{"Authorization": {"Bearer bob"}})
+	if err != nil {
+		t.Fatalf("Get returned an error: %v", err)
+	}
+	if resp.Cached {
+		t.Errorf("Expected a request with other credentials not to use the cached entry")
+	}
+	if resp, err = client.Get(server.URL, alice); err != nil || !resp.Cached {
+		t.Errorf("Expected the same credentials to revalidate the entry, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"feed/archive"
+	"feed/config"
+)
+
+// archiveWriter merges the items into the monthly archive; see package
+// archive.
+type archiveWriter struct {
+	Excerpts config.ExcerptConfig `yaml:"excerpts"`
+}
+
+func newArchiveWriter(cfg *config.Config, entry config.OutputConfig) (Writer, error) {
+	w := &archiveWriter{Excerpts: cfg.Excerpts.Archive}
+	if err := entry.Decode(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *archiveWriter) Write(ctx context.Context, set FeedSet) error {
+	log.Println("Updating monthly archive...")
+	// Months that have ended are only in the previous output
+	if err := set.CarryOver(archive.Dir); err != nil {
+		return err
+	}
+	index, written, err := archive.Write(set.Dir, excerpts(set.Items, w.Excerpts), set.Directory, set.Generated, set.Minify)
+	if err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+	for _, month := range index.Months {
+		set.Record(month.File)
+	}
+	set.Record(archive.IndexFile)
+	set.Meta.Archive = archive.IndexFile
+	log.Printf("Archive holds %d months; wrote %d files.", len(index.Months), len(written))
+	return nil
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressible lists the extensions of the files that get compressed
+// siblings.
+var compressible = map[string]bool{".json": true, ".ndjson": true, ".html": true}
+
+// compressor writes one kind of compressed sibling.
+type compressor struct {
+	ext    string // Extension appended to the file name
+	writer func(w io.Writer) io.WriteCloser
+}
+
+var (
+	gzipCompressor = compressor{".gz", func(w io.Writer) io.WriteCloser {
+		// The header carries no name or time, so unchanged files compress
+		// to the same bytes on every run
+		gz, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+		return gz
+	}}
+	brotliCompressor = compressor{".br", func(w io.Writer) io.WriteCloser {
+		return brotli.NewWriterLevel(w, brotli.BestCompression)
+	}}
+)
+
+// compressors returns the compressors enabled for the set.
+func (s FeedSet) compressors() []compressor {
+	var enabled []compressor
+	if s.Compression.Gzip {
+		enabled = append(enabled, gzipCompressor)
+	}
+	if s.Compression.Brotli {
+		enabled = append(enabled, brotliCompressor)
+	}
+	return enabled
+}
+
+// Compress writes the enabled compressed siblings of each JSON, NDJSON or
+// HTML file among names, slash-separated paths relative to the output
+// directory, and records them.
+func (s FeedSet) Compress(names ...string) error {
+	compressors := s.compressors()
+	if len(compressors) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] || !compressible[path.Ext(name)] {
+			continue
+		}
+		seen[name] = true
+		for _, c := range compressors {
+			if err := s.compress(name, c); err != nil {
+				return fmt.Errorf("failed to compress %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// compress streams name through c into its sibling, so large files such as
+// feed.ndjson are never held in memory.
+func (s FeedSet) compress(name string, c compressor) error {
+	in, err := os.Open(filepath.Join(s.Dir, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := s.Create(name + c.ext)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := c.writer(out)
+	if _, err := io.Copy(w, in); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
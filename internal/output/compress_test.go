@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+
+	"feed/config"
+	"feed/feeds"
+	"feed/internal/testutil"
+)
+
+func TestCompress(t *testing.T) {
+	set := newSet(t)
+	set.Compression = config.CompressionConfig{Gzip: true, Brotli: true}
+	original := []byte(`{"items": []}`)
+	if err := set.WriteFile("feed.json", original); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.WriteFile("style.css", []byte("body {}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Compress(set.Files()...); err != nil {
+		t.Fatalf("Compress returned an error: %v", err)
+	}
+
+	files := set.Files()
+	expected := []string{"feed.json", "style.css", "feed.json.gz", "feed.json.br"}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected files %v, got %v", expected, files)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("Expected file %d to be %s, got %s", i, expected[i], files[i])
+		}
+	}
+
+	gzData, _ := os.ReadFile(filepath.Join(set.Dir, "feed.json.gz"))
+	gz, err := gzip.NewReader(bytes.NewReader(gzData))
+	if err != nil {
+		t.Fatalf("Expected a gzip file: %v", err)
+	}
+	if data, _ := io.ReadAll(gz); !bytes.Equal(data, original) {
+		t.Errorf("Expected feed.json.gz to hold feed.json, got %q", data)
+	}
+	if !gz.ModTime.IsZero() || gz.Name != "" {
+		t.Errorf("Expected no name or time in the gzip header, got %q %v", gz.Name, gz.ModTime)
+	}
+
+	brData, _ := os.ReadFile(filepath.Join(set.Dir, "feed.json.br"))
+	if data, _ := io.ReadAll(brotli.NewReader(bytes.NewReader(brData))); !bytes.Equal(data, original) {
+		t.Errorf("Expected feed.json.br to hold feed.json, got %q", data)
+	}
+}
+
+func TestCompress_Disabled(t *testing.T) {
+	set := newSet(t)
+	if err := set.WriteFile("feed.json", []byte("[]")); err != nil {
+		t.Fatal(err)
+	}
+	if err := set.Compress(set.Files()...); err != nil {
+		t.Fatalf("Compress returned an error: %v", err)
+	}
+	if files := set.Files(); len(files) != 1 {
+		t.Errorf("Expected no compressed files, got %v", files)
+	}
+}
+
+func TestRun_ManifestHashes(t *testing.T) {
+	set := newSet(t, feeds.FeedItem{ID: "x:1", Platform: "x", Username: "godev"})
+	set.Compression.Gzip = true
+	writers, err := New(&config.Config{})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if err := Run(context.Background(), writers, set); err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+
+	var manifest Manifest
+	testutil.ReadJSON(t, set.Dir, ManifestFile, &manifest)
+	// authors, feed, meta and sources, each with a .gz sibling
+	if len(manifest.Files) != 8 {
+		t.Fatalf("Expected 8 files in the manifest, got %+v", manifest.Files)
+	}
+	for _, entry := range manifest.Files {
+		data, err := os.ReadFile(filepath.Join(set.Dir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			t.Fatalf("Expected %s to exist: %v", entry.Path, err)
+		}
+		sum := sha256.Sum256(data)
+		if entry.Size != int64(len(data)) || entry.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("Expected the size and hash of %s, got %+v", entry.Path, entry)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(set.Dir, ManifestFile+".gz")); err != nil {
+		t.Errorf("Expected manifest.json.gz to be written: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"feed/config"
+)
+
+// feedWriter writes the main aggregated feed, feed.json or
+// feed_page_N.json, and the full author and source tables.
+type feedWriter struct {
+	options pageOptions
+}
+
+func newFeedWriter(cfg *config.Config, entry config.OutputConfig) (Writer, error) {
+	options, err := newPageOptions(cfg, entry, cfg.Excerpts.Feed)
+	if err != nil {
+		return nil, err
+	}
+	return &feedWriter{options: options}, nil
+}
+
+func (w *feedWriter) Write(ctx context.Context, set FeedSet) error {
+	log.Println("Generating main aggregated feed...")
+	feedItems := excerpts(set.Items, w.options.Excerpts)
+	if w.options.paginated() {
+		pageName := func(pageNum int) string { return fmt.Sprintf("feed_page_%d.json", pageNum) }
+		paginatedFeeds := paginate(feedItems, w.options.PageSize, w.options.pagination, set.Directory, pageName)
+		set.Meta.TotalPages = len(paginatedFeeds)
+		set.Meta.Pagination = string(w.options.pagination)
+		for i, paginatedFeed := range paginatedFeeds {
+			filenames := []string{pageName(paginatedFeed.CurrentPage)}
+			if i == 0 && w.options.stable() {
+				filenames = append(filenames, "feed.json") // A fixed name for the head page
+			}
+			for _, filename := range filenames {
+				if err := set.WriteJSON(filename, paginatedFeed); err != nil {
+					return fmt.Errorf("error writing main feed page %d: %w", paginatedFeed.CurrentPage, err)
+				}
+			}
+			set.Meta.MainFeedPages = append(set.Meta.MainFeedPages, filepath.Join(set.Published, filenames[0]))
+		}
+		log.Printf("Successfully aggregated %d feed items into %d paginated files.", len(feedItems), len(paginatedFeeds))
+	} else {
+		// Write aggregated data to a single JSON file
+		if err := set.WriteJSON("feed.json", feedItems); err != nil {
+			return fmt.Errorf("error writing main feed: %w", err)
+		}
+		set.Meta.MainFeedPages = []string{filepath.Join(set.Published, "feed.json")}
+		log.Printf("Successfully aggregated %d feed items to %s", len(feedItems), filepath.Join(set.Published, "feed.json"))
+	}
+
+	// Write the full author and source tables, which the unpaginated feeds have no room for
+	for _, table := range []struct {
+		filename string
+		data     interface{}
+		metaPath *string
+	}{
+		{"authors.json", set.Directory.Authors, &set.Meta.Authors},
+		{"sources.json", set.Directory.Sources, &set.Meta.Sources},
+	} {
+		if err := set.WriteJSON(table.filename, table.data); err != nil {
+			return fmt.Errorf("error writing %s: %w", table.filename, err)
+		}
+		*table.metaPath = table.filename
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"feed/config"
+	"feed/feeds"
+	"feed/internal/testutil"
+)
+
+func TestFeedWriter_Single(t *testing.T) {
+	items := []feeds.FeedItem{
+		{ID: "x:3", Platform: "x", Timestamp: time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)},
+		{ID: "blog:2", Platform: "blog", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{ID: "x:1", Platform: "x", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	set := newSet(t, items...)
+	w, err := newFeedWriter(&config.Config{}, config.OutputConfig{Type: "feed"})
+	if err != nil {
+		t.Fatalf("newFeedWriter returned an error: %v", err)
+	}
+	if err := w.Write(context.Background(), set); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	var written []map[string]interface{}
+	testutil.ReadJSON(t, set.Dir, "feed.json", &written)
+	if len(written) != 3 {
+		t.Errorf("Expected 3 items in feed.json, got %d", len(written))
+	}
+	var authors map[string]interface{}
+	testutil.ReadJSON(t, set.Dir, "authors.json", &authors)
+	if set.Meta.Authors != "authors.json" || set.Meta.Sources != "sources.json" {
+		t.Errorf("Expected the tables in meta, got %+v", set.Meta)
+	}
+	if len(set.Meta.MainFeedPages) != 1 || set.Meta.MainFeedPages[0] != filepath.Join(set.Dir, "feed.json") {
+		t.Errorf("Expected feed.json as the only page, got %v", set.Meta.MainFeedPages)
+	}
+}
+
+func TestFeedWriter_Paginated(t *testing.T) {
+	items := []feeds.FeedItem{
+		{ID: "x:3", Platform: "x", Timestamp: time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)},
+		{ID: "blog:2", Platform: "blog", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{ID: "x:1", Platform: "x", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	set := newSet(t, items...)
+	entry := config.OutputConfig{Type: "feed", Options: map[string]interface{}{"pagination": "stable"}}
+	w, err := newFeedWriter(&config.Config{PageSize: 2}, entry)
+	if err != nil {
+		t.Fatalf("newFeedWriter returned an error: %v", err)
+	}
+	if err := w.Write(context.Background(), set); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	// Stable pages: the oldest two items on page 1, the newest alone on the head
+	var head, page1 PaginatedFeed
+	testutil.ReadJSON(t, set.Dir, "feed.json", &head)
+	testutil.ReadJSON(t, set.Dir, "feed_page_1.json", &page1)
+	if head.CurrentPage != 2 || head.TotalPages != 2 || len(head.Items) != 1 || *head.NextPage != "feed_page_1.json" {
+		t.Errorf("Unexpected head page: %+v", head)
+	}
+	if page1.TotalPages != 0 || len(page1.Items) != 2 || *page1.PrevPage != "feed_page_2.json" {
+		t.Errorf("Unexpected page 1: %+v", page1)
+	}
+	if set.Meta.TotalPages != 2 || set.Meta.Pagination != "stable" {
+		t.Errorf("Expected 2 stable pages in meta, got %+v", set.Meta)
+	}
+}
+
+func TestFeedWriter_Minified(t *testing.T) {
+	set := newSet(t, feeds.FeedItem{ID: "x:1", Platform: "x"})
+	set.Minify = true
+	w, err := newFeedWriter(&config.Config{}, config.OutputConfig{Type: "feed"})
+	if err != nil {
+		t.Fatalf("newFeedWriter returned an error: %v", err)
+	}
+	if err := w.Write(context.Background(), set); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(set.Dir, "feed.json"))
+	if err != nil {
+		t.Fatalf("Expected feed.json to be written: %v", err)
+	}
+	if bytes.Contains(data, []byte("\n")) || !bytes.HasPrefix(data, []byte(`[{"`)) {
+		t.Errorf("Expected minified JSON, got %s", data)
+	}
+}
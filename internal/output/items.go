@@ -0,0 +1,79 @@
+package output
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+
+	"feed/config"
+	"feed/feeds"
+)
+
+// ItemFile is the content of an individual item file. It has no tables to
+// refer to, so the item's author and source are included inline.
+type ItemFile struct {
+	feeds.FeedItem
+	Author *feeds.Author `json:"author,omitempty"`
+	Source *feeds.Source `json:"source,omitempty"`
+}
+
+// itemWriter writes a file per item into items/ and sets the items'
+// permalinks to them.
+type itemWriter struct{}
+
+func newItemWriter(cfg *config.Config, entry config.OutputConfig) (Writer, error) {
+	if err := entry.Decode(&struct{}{}); err != nil { // It has no options
+		return nil, err
+	}
+	return &itemWriter{}, nil
+}
+
+// itemFilenames names the item files by timestamp and a hash of the item's
+// Key, so that an item keeps its file, and its permalink, from run to run
+// however the items around it change. Items with the same key are numbered.
+func itemFilenames(items []feeds.FeedItem) []string {
+	names := make([]string, len(items))
+	seen := make(map[string]int)
+	for i, item := range items {
+		sum := sha256.Sum256([]byte(item.Key()))
+		name := fmt.Sprintf("%s_%x", item.Timestamp.UTC().Format("20060102150405"), sum[:6])
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			name = fmt.Sprintf("%s_%d", name, n)
+		}
+		names[i] = name + ".json"
+	}
+	return names
+}
+
+// Prepare sets the permalink of every item, so that the other outputs can
+// link to the item files.
+func (w *itemWriter) Prepare(set FeedSet) {
+	for i, name := range itemFilenames(set.Items) {
+		set.Items[i].Permalink = filepath.Join("items", name)
+	}
+}
+
+func (w *itemWriter) Write(ctx context.Context, set FeedSet) error {
+	log.Println("Generating individual item files...")
+	set.Meta.IndividualItems = "items/"
+	names := itemFilenames(set.Items)
+	for i, item := range set.Items {
+		filename := path.Join("items", names[i])
+		itemFile := ItemFile{FeedItem: item}
+		if author, ok := set.Directory.Authors[item.AuthorID]; ok {
+			itemFile.Author = &author
+		}
+		if source, ok := set.Directory.Sources[item.SourceID]; ok {
+			itemFile.Source = &source
+		}
+		if err := set.WriteJSON(filename, itemFile); err != nil {
+			return fmt.Errorf("error writing individual item file %s: %w", filename, err)
+		}
+	}
+	log.Printf("Generated %d individual item files.", len(set.Items))
+	return nil
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"feed/feeds"
+)
+
+func TestItemFilenames_Stable(t *testing.T) {
+	items := []feeds.FeedItem{
+		{ID: "x:3", Platform: "x", Timestamp: time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)},
+		{ID: "blog:2", Platform: "blog", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{ID: "x:1", Platform: "x", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	before := itemFilenames(items)
+
+	// A new item at the top must not rename the files of the others
+	newer := feeds.FeedItem{ID: "x:4", Platform: "x", Timestamp: time.Date(2025, 1, 4, 10, 0, 0, 0, time.UTC)}
+	after := itemFilenames(append([]feeds.FeedItem{newer}, items...))
+	for i, name := range before {
+		if after[i+1] != name {
+			t.Errorf("Expected item %d to keep %s, got %s", i, name, after[i+1])
+		}
+	}
+
+	// Duplicates are numbered
+	names := itemFilenames([]feeds.FeedItem{items[0], items[0]})
+	if names[1] != names[0][:len(names[0])-len(".json")]+"_2.json" {
+		t.Errorf("Expected the duplicate to be numbered, got %v", names)
+	}
+}
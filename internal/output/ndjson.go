@@ -0,0 +1,58 @@
+package output
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"feed/config"
+)
+
+// NDJSONFile is the name of the main feed in NDJSON format.
+const NDJSONFile = "feed.ndjson"
+
+// ndjsonWriter writes the items of the main feed to feed.ndjson, one JSON
+// object per line. Items are encoded one at a time straight into the file,
+// so the feed is never held in memory a second time.
+type ndjsonWriter struct {
+	Excerpts config.ExcerptConfig `yaml:"excerpts"`
+}
+
+func newNDJSONWriter(cfg *config.Config, entry config.OutputConfig) (Writer, error) {
+	w := &ndjsonWriter{Excerpts: cfg.Excerpts.Feed}
+	if err := entry.Decode(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *ndjsonWriter) Write(ctx context.Context, set FeedSet) error {
+	log.Println("Generating NDJSON feed...")
+	f, err := set.Create(NDJSONFile)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", NDJSONFile, err)
+	}
+	defer f.Close()
+
+	buf := bufio.NewWriter(f)
+	encoder := json.NewEncoder(buf) // Encode ends each item with a newline
+	for _, item := range set.Items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := encoder.Encode(item.Excerpt(w.Excerpts.Limit(item.Platform))); err != nil {
+			return fmt.Errorf("error writing %s: %w", NDJSONFile, err)
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("error writing %s: %w", NDJSONFile, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", NDJSONFile, err)
+	}
+	set.Meta.NDJSON = NDJSONFile
+	log.Printf("Successfully wrote %d items to %s", len(set.Items), NDJSONFile)
+	return nil
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"feed/config"
+	"feed/feeds"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	set := newSet(t,
+		feeds.FeedItem{ID: "x:3", Platform: "x", ContentText: "Newest", Timestamp: time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)},
+		feeds.FeedItem{ID: "blog:2", Platform: "blog", ContentText: "Middle", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)},
+		feeds.FeedItem{ID: "x:1", Platform: "x", ContentText: "Oldest", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+	)
+	entry := config.OutputConfig{Type: "ndjson", Options: map[string]interface{}{"excerpts": map[string]interface{}{"max_length": 4}}}
+	w, err := newNDJSONWriter(&config.Config{}, entry)
+	if err != nil {
+		t.Fatalf("newNDJSONWriter returned an error: %v", err)
+	}
+	if err := w.Write(context.Background(), set); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(set.Dir, NDJSONFile))
+	if err != nil {
+		t.Fatalf("Expected %s to be written: %v", NDJSONFile, err)
+	}
+	defer f.Close()
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var item struct {
+			ID          string `json:"id"`
+			ContentText string `json:"content_text"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("Expected a JSON object per line, got %q: %v", scanner.Text(), err)
+		}
+		if len([]rune(item.ContentText)) > 4 {
+			t.Errorf("Expected excerpts of at most 4 characters, got %q", item.ContentText)
+		}
+		ids = append(ids, item.ID)
+	}
+	if len(ids) != 3 || ids[0] != "x:3" || ids[2] != "x:1" {
+		t.Errorf("Expected the items newest first, got %v", ids)
+	}
+	if set.Meta.NDJSON != NDJSONFile {
+		t.Errorf("Expected %s in meta, got %q", NDJSONFile, set.Meta.NDJSON)
+	}
+	if files := set.Files(); len(files) != 1 || files[0] != NDJSONFile {
+		t.Errorf("Expected %s to be recorded, got %v", NDJSONFile, files)
+	}
+}
@@ -0,0 +1,285 @@
+// Package output writes the generated files. Each format is a Writer; the
+// writers to run, and their options, come from the outputs list in
+// config.yaml. They all receive the same FeedSet, write through it so that
+// every file is recorded, and describe what they wrote in meta.json.
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"feed/config"
+	"feed/feeds"
+)
+
+// MetaFile is the name of the metadata file, which is always written.
+const MetaFile = "meta.json"
+
+// Writer writes one output format for a FeedSet.
+type Writer interface {
+	Write(ctx context.Context, set FeedSet) error
+}
+
+// Preparer is implemented by writers whose files other outputs link to.
+// Prepare runs for every writer before any of them writes, so that links
+// (such as item permalinks) do not depend on the order of the writers.
+type Preparer interface {
+	Prepare(set FeedSet)
+}
+
+// Factory creates a writer from its entry in the outputs list. cfg holds the
+// global settings, which the entry's options override.
+type Factory func(cfg *config.Config, options config.OutputConfig) (Writer, error)
+
+// factories holds the writer types that can be listed in outputs. A new
+// format is added here, next to the writers in this package.
+var factories = map[string]Factory{
+	"items":     newItemWriter,
+	"feed":      newFeedWriter,
+	"platforms": newPlatformWriter,
+	"tags":      newTagWriter,
+	"archive":   newArchiveWriter,
+	"site":      newSiteWriter,
+	"search":    newSearchWriter,
+	"ndjson":    newNDJSONWriter,
+	"sqlite":    newSQLiteWriter,
+}
+
+// Types returns the names of the writer types.
+func Types() []string {
+	var names []string
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Meta is the content of meta.json: an overview of the generated files.
+type Meta struct {
+	SchemaVersion   int               `json:"schema_version"`
+	TotalItems      int               `json:"total_items"`
+	TotalPages      int               `json:"total_pages,omitempty"`
+	Pagination      string            `json:"pagination,omitempty"` // "newest" or "stable", for paginated feeds
+	MainFeedPages   []string          `json:"main_feed_pages,omitempty"`
+	PlatformFeeds   map[string]string `json:"platform_feeds,omitempty"`
+	TagFeeds        map[string]string `json:"tag_feeds,omitempty"`
+	Tags            string            `json:"tags,omitempty"`
+	Archive         string            `json:"archive,omitempty"` // Index of the monthly archive, if generated
+	IndividualItems string            `json:"individual_items_directory,omitempty"`
+	Authors         string            `json:"authors,omitempty"`
+	Sources         string            `json:"sources,omitempty"`
+	Site            string            `json:"site,omitempty"`   // Entry page of the HTML site, if generated
+	Search          string            `json:"search,omitempty"` // Manifest of the search index, if generated
+	NDJSON          string            `json:"ndjson,omitempty"` // Main feed with one item per line, if generated
+	SQLite          string            `json:"sqlite,omitempty"` // SQLite database of the items, if generated
+	Manifest        string            `json:"manifest"`         // List of the files written by the run
+}
+
+// FeedSet is what the writers write: the items of a run and where to put
+// them. It is passed by value, but copies share the items, the metadata and
+// the record of written files.
+type FeedSet struct {
+	Dir       string           // Directory to write into; a staging directory while Run writes
+	Published string           // Output directory, which holds the previous run's files until Run swaps Dir in
+	Items     []feeds.FeedItem // Newest first, with their full content
+	Directory feeds.Directory  // Authors and sources referenced by the items
+	Generated time.Time        // Time of the run
+	Meta      *Meta            // Writers record the locations of their outputs here
+
+	Minify      bool                     // Write JSON without indentation
+	Compression config.CompressionConfig // Compressed siblings that Run adds to JSON, NDJSON and HTML files
+
+	files *[]string
+}
+
+// NewFeedSet returns a FeedSet for items, to be written into dir.
+func NewFeedSet(dir string, items []feeds.FeedItem, directory feeds.Directory, generated time.Time) FeedSet {
+	return FeedSet{
+		Dir:       dir,
+		Published: dir,
+		Items:     items,
+		Directory: directory,
+		Generated: generated,
+		Meta: &Meta{
+			SchemaVersion: feeds.SchemaVersion,
+			TotalItems:    len(items),
+			PlatformFeeds: make(map[string]string),
+			Manifest:      ManifestFile,
+		},
+		files: new([]string),
+	}
+}
+
+// WriteJSON writes v as JSON to name, a slash-separated path relative to
+// the output directory, creating directories as needed. It is indented
+// unless the set is minified.
+func (s FeedSet) WriteJSON(name string, v interface{}) error {
+	var data []byte
+	var err error
+	if s.Minify {
+		data, err = json.Marshal(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	return s.WriteFile(name, data)
+}
+
+// WriteFile writes data to name, a slash-separated path relative to the
+// output directory, creating directories as needed.
+func (s FeedSet) WriteFile(name string, data []byte) error {
+	filename := filepath.Join(s.Dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return err
+	}
+	s.Record(name)
+	return nil
+}
+
+// Create creates name, a slash-separated path relative to the output
+// directory, for a writer that streams its content, creating directories as
+// needed. The file is recorded; the caller closes it.
+func (s FeedSet) Create(name string) (*os.File, error) {
+	filename := filepath.Join(s.Dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	s.Record(name)
+	return f, nil
+}
+
+// Record adds files that a writer wrote itself, as slash-separated paths
+// relative to the output directory, to the record of written files.
+func (s FeedSet) Record(names ...string) {
+	*s.files = append(*s.files, names...)
+}
+
+// Files returns the files written so far, in the order they were written.
+func (s FeedSet) Files() []string {
+	return append([]string(nil), *s.files...)
+}
+
+// New creates the writers listed in outputs, or those implied by the
+// generate_* settings, site and search when the list is empty.
+func New(cfg *config.Config) ([]Writer, error) {
+	entries := cfg.Outputs
+	if len(entries) == 0 {
+		entries = legacyOutputs(cfg)
+	} else {
+		// The site links to platform pages only if they are written
+		listed := *cfg
+		listed.GeneratePlatformFeeds = false
+		for _, entry := range entries {
+			listed.GeneratePlatformFeeds = listed.GeneratePlatformFeeds || entry.Type == "platforms"
+		}
+		cfg = &listed
+	}
+	var writers []Writer
+	for _, entry := range entries {
+		factory, ok := factories[entry.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown output type %q (available: %v)", entry.Type, Types())
+		}
+		writer, err := factory(cfg, entry)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, writer)
+	}
+	return writers, nil
+}
+
+// legacyOutputs lists the writers that the settings predating the outputs
+// list turn on, in the order they used to be written.
+func legacyOutputs(cfg *config.Config) []config.OutputConfig {
+	var entries []config.OutputConfig
+	add := func(enabled bool, name string) {
+		if enabled {
+			entries = append(entries, config.OutputConfig{Type: name})
+		}
+	}
+	add(cfg.GenerateIndividualItemFiles, "items")
+	add(cfg.GeneratePlatformFeeds, "platforms")
+	add(cfg.GenerateTagFeeds, "tags")
+	add(true, "feed")
+	add(cfg.GenerateNDJSON, "ndjson")
+	add(cfg.GenerateArchive, "archive")
+	add(cfg.GenerateSQLite, "sqlite")
+	add(cfg.Site.Enabled, "site")
+	add(cfg.Search.Enabled, "search")
+	return entries
+}
+
+// Run prepares and runs writers in order, then writes meta.json, the
+// compressed siblings of the JSON and HTML files, and manifest.json. The
+// files are written into a staging directory next to set.Dir, which
+// replaces set.Dir only once every writer has succeeded, so files that the
+// run did not produce are pruned. On error set.Dir is left as it was.
+func Run(ctx context.Context, writers []Writer, set FeedSet) error {
+	stage, err := newStage(set.Dir)
+	if err != nil {
+		return err
+	}
+	defer stage.discard()
+
+	staged := set
+	staged.Dir = stage.dir
+	for _, writer := range writers {
+		if preparer, ok := writer.(Preparer); ok {
+			preparer.Prepare(staged)
+		}
+	}
+	for _, writer := range writers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := writer.Write(ctx, staged); err != nil {
+			return err
+		}
+	}
+	if err := staged.WriteJSON(MetaFile, set.Meta); err != nil {
+		return err
+	}
+	if err := staged.Compress(set.Files()...); err != nil {
+		return err
+	}
+	manifest, err := newManifest(staged.Dir, set.Files(), set.Generated)
+	if err != nil {
+		return err
+	}
+	if err := staged.WriteJSON(ManifestFile, manifest); err != nil {
+		return err
+	}
+	if err := staged.Compress(ManifestFile); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	pruned, err := stage.commit(set.Files())
+	if err != nil {
+		return err
+	}
+	if len(pruned) > 0 {
+		log.Printf("Pruned %d files that are no longer generated from %s.", len(pruned), set.Dir)
+	}
+	log.Printf("Successfully generated metadata to %s", filepath.Join(set.Dir, MetaFile))
+	return nil
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"feed/config"
+	"feed/feeds"
+	"feed/internal/testutil"
+)
+
+// newSet returns a FeedSet of items that writes to a temporary directory.
+func newSet(t *testing.T, items ...feeds.FeedItem) FeedSet {
+	t.Helper()
+	return NewFeedSet(t.TempDir(), items, feeds.BuildDirectory(items), time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC))
+}
+
+// writerTypes returns the type of each writer, e.g. "*output.feedWriter".
+func writerTypes(writers []Writer) []string {
+	var types []string
+	for _, w := range writers {
+		types = append(types, reflect.TypeOf(w).String())
+	}
+	return types
+}
+
+func TestNew_LegacySettings(t *testing.T) {
+	cfg := &config.Config{GenerateIndividualItemFiles: true, GenerateTagFeeds: true}
+	cfg.Search.Enabled = true
+	writers, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	expected := []string{"*output.itemWriter", "*output.tagWriter", "*output.feedWriter", "*output.searchWriter"}
+	if got := writerTypes(writers); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected writers %v, got %v", expected, got)
+	}
+}
+
+func TestNew_Outputs(t *testing.T) {
+	cfg := &config.Config{
+		GenerateIndividualItemFiles: true, // Ignored when outputs is set
+		Outputs: []config.OutputConfig{
+			{Type: "platforms", Options: map[string]interface{}{"page_size": 3}},
+			{Type: "feed"},
+		},
+	}
+	writers, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	expected := []string{"*output.platformWriter", "*output.feedWriter"}
+	if got := writerTypes(writers); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected writers %v, got %v", expected, got)
+	}
+	if pageSize := writers[0].(*platformWriter).options.PageSize; pageSize != 3 {
+		t.Errorf("Expected the entry's page size 3, got %d", pageSize)
+	}
+
+	for _, entry := range []config.OutputConfig{
+		{Type: "atom"},
+		{Type: "feed", Options: map[string]interface{}{"colour": "blue"}},
+		{Type: "feed", Options: map[string]interface{}{"pagination": "sideways"}},
+	} {
+		if _, err := New(&config.Config{Outputs: []config.OutputConfig{entry}}); err == nil {
+			t.Errorf("Expected an error for %+v", entry)
+		}
+	}
+}
+
+// recorder is a writer that records the permalinks it sees.
+type recorder struct {
+	seen []string
+}
+
+func (r *recorder) Write(ctx context.Context, set FeedSet) error {
+	for _, item := range set.Items {
+		r.seen = append(r.seen, item.Permalink)
+	}
+	return set.WriteJSON("custom/out.json", len(set.Items))
+}
+
+func TestNew_Prepare(t *testing.T) {
+	r := &recorder{}
+	factories["recorder"] = func(cfg *config.Config, entry config.OutputConfig) (Writer, error) { return r, nil }
+	defer delete(factories, "recorder")
+
+	// The item writer comes second but prepares permalinks before any writes
+	writers, err := New(&config.Config{Outputs: []config.OutputConfig{{Type: "recorder"}, {Type: "items"}}})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	items := []feeds.FeedItem{
+		{ID: "x:3", Platform: "x", Timestamp: time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)},
+		{ID: "blog:2", Platform: "blog", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{ID: "x:1", Platform: "x", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	set := newSet(t, items...)
+	if err := Run(context.Background(), writers, set); err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+	if len(r.seen) != 3 || r.seen[0] != filepath.Join("items", "20250103100000_e4bcc385d467.json") {
+		t.Errorf("Expected the custom writer to see permalinks, got %v", r.seen)
+	}
+
+	files := set.Files()
+	sort.Strings(files)
+	expected := []string{"custom/out.json", "items/20250101100000_0b788078937c.json", "items/20250102100000_0b4cd7dddcdd.json", "items/20250103100000_e4bcc385d467.json", "manifest.json", "meta.json"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected files %v, got %v", expected, files)
+	}
+
+	var meta Meta
+	testutil.ReadJSON(t, set.Dir, MetaFile, &meta)
+	if meta.TotalItems != 3 || meta.IndividualItems != "items/" {
+		t.Errorf("Unexpected meta.json: %+v", meta)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	set := newSet(t)
+	if err := Run(ctx, []Writer{&recorder{}}, set); err == nil {
+		t.Errorf("Expected an error for a cancelled context")
+	}
+	if files := set.Files(); len(files) != 0 {
+		t.Errorf("Expected nothing to be written, got %v", files)
+	}
+}
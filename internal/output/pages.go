@@ -0,0 +1,98 @@
+package output
+
+import (
+	"feed/config"
+	"feed/feeds"
+)
+
+// PaginatedFeed represents the structure for a paginated JSON output.
+type PaginatedFeed struct {
+	SchemaVersion int              `json:"schema_version"`
+	Items         []feeds.FeedItem `json:"items"`
+	CurrentPage   int              `json:"current_page"`
+	TotalPages    int              `json:"total_pages,omitempty"` // Only on the newest page with stable pagination
+	NextPage      *string          `json:"next_page,omitempty"`
+	PrevPage      *string          `json:"prev_page,omitempty"`
+	// Authors and Sources hold the entries referenced by this page's items
+	Authors map[string]feeds.Author `json:"authors,omitempty"`
+	Sources map[string]feeds.Source `json:"sources,omitempty"`
+}
+
+// pageOptions are the options of the writers of paginated feeds. They
+// default to the global page_size and pagination settings.
+type pageOptions struct {
+	PageSize   int                  `yaml:"page_size"`
+	Pagination string               `yaml:"pagination"`
+	Excerpts   config.ExcerptConfig `yaml:"excerpts"`
+
+	pagination feeds.Pagination
+}
+
+// newPageOptions decodes the options of entry on top of the global settings
+// and excerpts.
+func newPageOptions(cfg *config.Config, entry config.OutputConfig, excerpts config.ExcerptConfig) (pageOptions, error) {
+	options := pageOptions{PageSize: cfg.PageSize, Pagination: cfg.Pagination, Excerpts: excerpts}
+	if err := entry.Decode(&options); err != nil {
+		return options, err
+	}
+	pagination, err := feeds.ParsePagination(options.Pagination)
+	if err != nil {
+		return options, err
+	}
+	options.pagination = pagination
+	return options, nil
+}
+
+// paginated reports whether feeds are split into pages.
+func (o pageOptions) paginated() bool {
+	return o.PageSize > 1
+}
+
+// stable reports whether pages are counted from the oldest item, with the
+// newest page copied to the feed's unnumbered file.
+func (o pageOptions) stable() bool {
+	return o.paginated() && o.pagination == feeds.PaginationStable
+}
+
+// paginate splits items into the pages of a paginated feed, newest first,
+// linked to each other by the file names pageName gives their numbers. In
+// stable mode only the newest page has total_pages, so that the other pages
+// do not change as items are added.
+func paginate(items []feeds.FeedItem, pageSize int, mode feeds.Pagination, directory feeds.Directory, pageName func(int) string) []PaginatedFeed {
+	pages := feeds.Paginate(items, pageSize, mode)
+	paginatedFeeds := make([]PaginatedFeed, len(pages))
+	for i, page := range pages {
+		pageDirectory := directory.For(page.Items)
+		paginatedFeed := PaginatedFeed{
+			SchemaVersion: feeds.SchemaVersion,
+			Items:         page.Items,
+			CurrentPage:   page.Number,
+			Authors:       pageDirectory.Authors,
+			Sources:       pageDirectory.Sources,
+		}
+		if i == 0 || mode != feeds.PaginationStable {
+			paginatedFeed.TotalPages = len(pages)
+		}
+		if page.Older > 0 {
+			nextPage := pageName(page.Older)
+			paginatedFeed.NextPage = &nextPage
+		}
+		if page.Newer > 0 {
+			prevPage := pageName(page.Newer)
+			paginatedFeed.PrevPage = &prevPage
+		}
+		paginatedFeeds[i] = paginatedFeed
+	}
+	return paginatedFeeds
+}
+
+// excerpts returns copies of items with their content shortened to the
+// limits for their platform. Items keep their full content when no limit
+// applies.
+func excerpts(items []feeds.FeedItem, limits config.ExcerptConfig) []feeds.FeedItem {
+	shortened := make([]feeds.FeedItem, len(items))
+	for i, item := range items {
+		shortened[i] = item.Excerpt(limits.Limit(item.Platform))
+	}
+	return shortened
+}
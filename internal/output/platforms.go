@@ -0,0 +1,81 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"feed/config"
+	"feed/feeds"
+)
+
+// platformWriter writes a feed per platform into platforms/: PLATFORM.json,
+// or PLATFORM_page_N.json when paginated.
+type platformWriter struct {
+	options pageOptions
+}
+
+func newPlatformWriter(cfg *config.Config, entry config.OutputConfig) (Writer, error) {
+	options, err := newPageOptions(cfg, entry, cfg.Excerpts.PlatformFeeds)
+	if err != nil {
+		return nil, err
+	}
+	return &platformWriter{options: options}, nil
+}
+
+func (w *platformWriter) Write(ctx context.Context, set FeedSet) error {
+	log.Println("Generating platform-specific feeds...")
+	// Platform labels are free text from the config, so feeds are grouped
+	// by the labels' slugs, which are safe to use as file names
+	platformItems := make(map[string][]feeds.FeedItem)
+	labels := make(map[string]string)
+	var slugs []string
+	for _, item := range excerpts(set.Items, w.options.Excerpts) {
+		platformSlug := feeds.TagSlug(item.Platform)
+		if platformSlug == "" {
+			continue
+		}
+		if _, ok := platformItems[platformSlug]; !ok {
+			slugs = append(slugs, platformSlug)
+			labels[platformSlug] = item.Platform
+		}
+		platformItems[platformSlug] = append(platformItems[platformSlug], item)
+	}
+	sort.Strings(slugs)
+
+	for _, platformSlug := range slugs {
+		platform, items := labels[platformSlug], platformItems[platformSlug]
+		log.Printf("Generating feed for platform: %s with %d items...", platform, len(items))
+
+		if !w.options.paginated() {
+			// Single file for platform feed
+			filename := fmt.Sprintf("%s.json", platformSlug)
+			if err := set.WriteJSON(path.Join("platforms", filename), items); err != nil {
+				return fmt.Errorf("error writing feed for platform %s: %w", platform, err)
+			}
+			set.Meta.PlatformFeeds[platform] = filepath.Join("platforms", filename)
+			continue
+		}
+
+		pageName := func(pageNum int) string { return fmt.Sprintf("%s_page_%d.json", platformSlug, pageNum) }
+		for i, paginatedFeed := range paginate(items, w.options.PageSize, w.options.pagination, set.Directory, pageName) {
+			filenames := []string{pageName(paginatedFeed.CurrentPage)}
+			if i == 0 && w.options.stable() {
+				filenames = append(filenames, fmt.Sprintf("%s.json", platformSlug))
+			}
+			for _, filename := range filenames {
+				if err := set.WriteJSON(path.Join("platforms", filename), paginatedFeed); err != nil {
+					return fmt.Errorf("error writing feed for platform %s page %d: %w", platform, paginatedFeed.CurrentPage, err)
+				}
+			}
+			if i == 0 { // Store link to the newest page of each platform feed
+				set.Meta.PlatformFeeds[platform] = filepath.Join("platforms", filenames[len(filenames)-1])
+			}
+		}
+	}
+	log.Println("Finished generating platform-specific feeds.")
+	return nil
+}
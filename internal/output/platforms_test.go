@@ -0,0 +1,79 @@
+package output
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"feed/config"
+	"feed/feeds"
+	"feed/internal/testutil"
+)
+
+func TestPlatformWriter(t *testing.T) {
+	set := newSet(t,
+		feeds.FeedItem{ID: "x:2", Platform: "x"},
+		feeds.FeedItem{ID: "blog:1", Platform: "blog"},
+		feeds.FeedItem{ID: "x:1", Platform: "x"},
+	)
+	w, err := newPlatformWriter(&config.Config{PageSize: 2}, config.OutputConfig{Type: "platforms"})
+	if err != nil {
+		t.Fatalf("newPlatformWriter returned an error: %v", err)
+	}
+	if err := w.Write(context.Background(), set); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	var x PaginatedFeed
+	testutil.ReadJSON(t, set.Dir, "platforms/x_page_1.json", &x)
+	if len(x.Items) != 2 || x.CurrentPage != 1 || x.TotalPages != 1 {
+		t.Errorf("Expected both x items on page 1, got %+v", x)
+	}
+	if set.Meta.PlatformFeeds["blog"] != filepath.Join("platforms", "blog_page_1.json") {
+		t.Errorf("Expected the blog feed in meta, got %v", set.Meta.PlatformFeeds)
+	}
+}
+
+func TestPlatformWriter_Excerpts(t *testing.T) {
+	set := newSet(t, feeds.FeedItem{ID: "blog:1", Platform: "blog", ContentText: "Middle"})
+	entry := config.OutputConfig{Type: "platforms", Options: map[string]interface{}{"excerpts": map[string]interface{}{"max_length": 4}}}
+	w, err := newPlatformWriter(&config.Config{}, entry)
+	if err != nil {
+		t.Fatalf("newPlatformWriter returned an error: %v", err)
+	}
+	if err := w.Write(context.Background(), set); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	var blog []struct {
+		ContentText string `json:"content_text"`
+		Truncated   bool   `json:"truncated"`
+	}
+	testutil.ReadJSON(t, set.Dir, "platforms/blog.json", &blog)
+	if len(blog) != 1 || !blog[0].Truncated || blog[0].ContentText != "Mid…" {
+		t.Errorf("Expected the excerpt of the blog item, got %+v", blog)
+	}
+	if set.Items[0].Truncated {
+		t.Errorf("Expected the FeedSet's items to keep their full content")
+	}
+}
+
+func TestPlatformWriter_UnsafeLabel(t *testing.T) {
+	set := newSet(t, feeds.FeedItem{ID: "blog:1", Platform: "../My Blog"})
+	w, err := newPlatformWriter(&config.Config{}, config.OutputConfig{Type: "platforms"})
+	if err != nil {
+		t.Fatalf("newPlatformWriter returned an error: %v", err)
+	}
+	if err := w.Write(context.Background(), set); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	var blog []map[string]interface{}
+	testutil.ReadJSON(t, set.Dir, "platforms/my-blog.json", &blog)
+	if len(blog) != 1 {
+		t.Errorf("Expected the item under its slug, got %d items", len(blog))
+	}
+	if set.Meta.PlatformFeeds["../My Blog"] != filepath.Join("platforms", "my-blog.json") {
+		t.Errorf("Expected the slugged feed in meta, got %v", set.Meta.PlatformFeeds)
+	}
+}
@@ -0,0 +1,44 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"feed/config"
+	"feed/search"
+)
+
+// searchWriter writes the client-side search index; see package search.
+type searchWriter struct {
+	index *search.Index
+}
+
+func newSearchWriter(cfg *config.Config, entry config.OutputConfig) (Writer, error) {
+	options := cfg.Search
+	if err := entry.Decode(&options); err != nil {
+		return nil, err
+	}
+	index, err := search.New(search.Options{
+		Fields:            options.Fields,
+		Language:          options.Language,
+		TermShards:        options.TermShards,
+		DocumentsPerShard: options.DocumentsPerShard,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid search setting: %w", err)
+	}
+	return &searchWriter{index: index}, nil
+}
+
+func (w *searchWriter) Write(ctx context.Context, set FeedSet) error {
+	log.Println("Generating search index...")
+	written, err := w.index.Write(set.Dir, set.Items)
+	set.Record(written...)
+	if err != nil {
+		return fmt.Errorf("error writing search index: %w", err)
+	}
+	set.Meta.Search = search.ManifestFile
+	log.Printf("Generated %d search index files.", len(written))
+	return nil
+}
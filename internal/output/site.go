@@ -0,0 +1,75 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"feed/config"
+	"feed/feeds"
+	"feed/site"
+)
+
+// siteOptions are the options of the site writer: those of the site block,
+// and the pagination and excerpts of the feed it mirrors.
+type siteOptions struct {
+	config.SiteConfig `yaml:",inline"`
+	PageSize          int                  `yaml:"page_size"`
+	Pagination        string               `yaml:"pagination"`
+	PlatformPages     bool                 `yaml:"platform_pages"`
+	Excerpts          config.ExcerptConfig `yaml:"excerpts"`
+}
+
+// siteWriter renders the static HTML site; see package site.
+type siteWriter struct {
+	site *site.Site
+}
+
+func newSiteWriter(cfg *config.Config, entry config.OutputConfig) (Writer, error) {
+	options := siteOptions{
+		SiteConfig:    cfg.Site,
+		PageSize:      cfg.PageSize,
+		Pagination:    cfg.Pagination,
+		PlatformPages: cfg.GeneratePlatformFeeds,
+		Excerpts:      cfg.Excerpts.Feed,
+	}
+	if err := entry.Decode(&options); err != nil {
+		return nil, err
+	}
+	pagination, err := feeds.ParsePagination(options.Pagination)
+	if err != nil {
+		return nil, err
+	}
+	title := options.Title
+	if title == "" {
+		title = "Feed"
+	}
+	htmlSite, err := site.New(site.Options{
+		Title:         title,
+		Description:   options.Description,
+		BaseURL:       options.BaseURL,
+		ThemeDir:      options.ThemeDir,
+		PageSize:      options.PageSize,
+		PlatformPages: options.PlatformPages,
+		Pagination:    pagination,
+		Excerpt: func(item feeds.FeedItem) feeds.FeedItem {
+			return item.Excerpt(options.Excerpts.Limit(item.Platform))
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading HTML theme: %w", err)
+	}
+	return &siteWriter{site: htmlSite}, nil
+}
+
+func (w *siteWriter) Write(ctx context.Context, set FeedSet) error {
+	log.Println("Generating HTML site...")
+	pages, err := w.site.Write(set.Dir, set.Items, set.Directory)
+	set.Record(pages...)
+	if err != nil {
+		return fmt.Errorf("error generating HTML site: %w", err)
+	}
+	set.Meta.Site = "index.html"
+	log.Printf("Generated %d HTML site files.", len(pages))
+	return nil
+}
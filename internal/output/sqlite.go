@@ -0,0 +1,32 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"feed/config"
+	"feed/sqlite"
+)
+
+// sqliteWriter exports the items to feed.sqlite; see package sqlite.
+type sqliteWriter struct{}
+
+func newSQLiteWriter(cfg *config.Config, entry config.OutputConfig) (Writer, error) {
+	if err := entry.Decode(&struct{}{}); err != nil { // It has no options
+		return nil, err
+	}
+	return &sqliteWriter{}, nil
+}
+
+func (w *sqliteWriter) Write(ctx context.Context, set FeedSet) error {
+	log.Println("Exporting SQLite database...")
+	if err := sqlite.Write(filepath.Join(set.Dir, sqlite.File), set.Items, set.Directory, set.Generated); err != nil {
+		return fmt.Errorf("error writing %s: %w", sqlite.File, err)
+	}
+	set.Record(sqlite.File)
+	set.Meta.SQLite = sqlite.File
+	log.Printf("Successfully exported %d items to %s", len(set.Items), sqlite.File)
+	return nil
+}
@@ -0,0 +1,33 @@
+package output
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"feed/config"
+	"feed/feeds"
+	"feed/internal/testutil"
+)
+
+func TestSQLiteWriter(t *testing.T) {
+	set := newSet(t, feeds.FeedItem{ID: "x:1", Platform: "x", Username: "godev"})
+	writers, err := New(&config.Config{Outputs: []config.OutputConfig{{Type: "sqlite"}}})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if err := Run(context.Background(), writers, set); err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+
+	// No journal is left next to the database
+	expected := []string{"feed.sqlite", "manifest.json", "meta.json"}
+	if got := listFiles(t, set.Dir); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected files %v, got %v", expected, got)
+	}
+	var meta Meta
+	testutil.ReadJSON(t, set.Dir, MetaFile, &meta)
+	if meta.SQLite != "feed.sqlite" {
+		t.Errorf("Expected feed.sqlite in meta, got %q", meta.SQLite)
+	}
+}
@@ -0,0 +1,286 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"feed/feeds"
+)
+
+// ManifestFile is the name of the list of files written by a run.
+const ManifestFile = "manifest.json"
+
+// Manifest is the content of manifest.json.
+type Manifest struct {
+	SchemaVersion int             `json:"schema_version"`
+	Generated     time.Time       `json:"generated"`
+	Files         []ManifestEntry `json:"files"` // Sorted by path; manifest.json and its compressed siblings are not listed
+}
+
+// ManifestEntry describes one file in the manifest.
+type ManifestEntry struct {
+	Path   string `json:"path"`   // Slash-separated, relative to the output directory
+	Size   int64  `json:"size"`   // In bytes
+	SHA256 string `json:"sha256"` // Hex-encoded SHA-256 hash of the content
+}
+
+// newManifest lists files, relative to dir, with their sizes and hashes.
+func newManifest(dir string, files []string, generated time.Time) (Manifest, error) {
+	manifest := Manifest{SchemaVersion: feeds.SchemaVersion, Generated: generated, Files: []ManifestEntry{}}
+	seen := make(map[string]bool)
+	for _, name := range files {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		entry, err := hashFile(dir, name)
+		if err != nil {
+			return Manifest{}, err
+		}
+		manifest.Files = append(manifest.Files, entry)
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+	return manifest, nil
+}
+
+func hashFile(dir, name string) (ManifestEntry, error) {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return ManifestEntry{}, fmt.Errorf("failed to hash %s: %w", name, err)
+	}
+	return ManifestEntry{Path: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// CarryOver copies the JSON files under dir, a slash-separated directory
+// relative to the output directory, from the published output into the
+// set's directory, so that a writer can update them. Compressed siblings are
+// left behind; Compress writes them again for the files that are kept. It
+// does nothing when the set writes into the published output directly or
+// dir does not exist there. The copies are not recorded; the writer records
+// the files it keeps.
+func (s FeedSet) CarryOver(dir string) error {
+	if s.Published == "" || filepath.Clean(s.Published) == filepath.Clean(s.Dir) {
+		return nil
+	}
+	root := filepath.Join(s.Published, filepath.FromSlash(dir))
+	err := filepath.WalkDir(root, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && filename == root {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() || filepath.Ext(filename) != ".json" {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Published, filename)
+		if err != nil {
+			return err
+		}
+		return copyFile(filename, filepath.Join(s.Dir, rel))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to carry over %s: %w", dir, err)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// stage is a temporary directory next to the output directory, on the same
+// file system, that a run writes into before it replaces the output.
+type stage struct {
+	dir       string // Staging directory
+	target    string // Output directory
+	committed bool
+}
+
+func newStage(target string) (*stage, error) {
+	target = filepath.Clean(target)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, err
+	}
+	if err := restore(target); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(target), stagePrefix(target))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	// MkdirTemp creates the directory for the owner only
+	if err := os.Chmod(dir, 0755); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &stage{dir: dir, target: target}, nil
+}
+
+// stagePrefix is the name prefix of the staging directories of target.
+func stagePrefix(target string) string {
+	return "." + filepath.Base(target) + "-"
+}
+
+// restore puts back an output directory that an earlier run moved aside but
+// did not get to replace, having been stopped between the two renames of
+// replace.
+func restore(target string) error {
+	if _, err := os.Lstat(target); !os.IsNotExist(err) {
+		return nil
+	}
+	previous, err := filepath.Glob(filepath.Join(filepath.Dir(target), stagePrefix(target)+"*.old"))
+	if err != nil || len(previous) == 0 {
+		return nil
+	}
+	if err := os.Rename(previous[0], target); err != nil {
+		return fmt.Errorf("failed to restore %s from %s: %w", target, previous[0], err)
+	}
+	log.Printf("Restored %s, which an interrupted run had moved aside.", target)
+	return nil
+}
+
+// commit swaps the staging directory in for the output directory and
+// returns the files of the previous output that are not in files.
+//
+// The output directory is a symlink to the current staging directory. A new
+// symlink to this stage replaces it with a single rename, which is atomic,
+// so readers always find a complete output. An output that is still a plain
+// directory, as left by older versions, is turned into the symlink once by
+// replace, as is the stage itself where symlinks are not supported.
+func (s *stage) commit(files []string) ([]string, error) {
+	pruned, err := stale(s.target, files)
+	if err != nil {
+		return nil, err
+	}
+	link := s.dir + ".link"
+	if err := os.Symlink(filepath.Base(s.dir), link); err != nil {
+		if err := s.replace(s.dir); err != nil {
+			return nil, err
+		}
+		return pruned, nil
+	}
+	info, err := os.Lstat(s.target)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		if err := s.replace(link); err != nil {
+			os.Remove(link)
+			return nil, err
+		}
+		s.committed = true
+		return pruned, nil
+	}
+
+	previous, err := os.Readlink(s.target)
+	if err != nil {
+		os.Remove(link)
+		return nil, err
+	}
+	if err := os.Rename(link, s.target); err != nil {
+		os.Remove(link)
+		return nil, fmt.Errorf("failed to replace %s: %w", s.target, err)
+	}
+	s.committed = true
+	// Only remove the previous stage, not a directory the link was pointed
+	// at by hand
+	if !filepath.IsAbs(previous) {
+		previous = filepath.Join(filepath.Dir(s.target), previous)
+	}
+	if filepath.Dir(previous) == filepath.Dir(s.target) && strings.HasPrefix(filepath.Base(previous), stagePrefix(s.target)) {
+		if err := os.RemoveAll(previous); err != nil {
+			return nil, fmt.Errorf("failed to remove the previous output: %w", err)
+		}
+	}
+	return pruned, nil
+}
+
+// replace moves the output directory aside and renames path, the stage or a
+// symlink to it, into its place, putting the output back if that fails.
+// Between the two renames the output directory does not exist; newStage
+// restores it if a run is stopped there.
+func (s *stage) replace(path string) error {
+	previous := s.dir + ".old"
+	if err := os.Rename(s.target, previous); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move %s aside: %w", s.target, err)
+	}
+	if err := os.Rename(path, s.target); err != nil {
+		os.Rename(previous, s.target)
+		return fmt.Errorf("failed to replace %s: %w", s.target, err)
+	}
+	if err := os.RemoveAll(previous); err != nil {
+		return fmt.Errorf("failed to remove the previous output: %w", err)
+	}
+	return nil
+}
+
+// discard removes the staging directory if it was not committed.
+func (s *stage) discard() {
+	if !s.committed {
+		os.RemoveAll(s.dir)
+	}
+}
+
+// stale lists the files under dir, as slash-separated relative paths, that
+// are not in files.
+func stale(dir string, files []string) ([]string, error) {
+	kept := make(map[string]bool)
+	for _, name := range files {
+		kept[name] = true
+	}
+	// The output directory is usually a symlink, which WalkDir does not
+	// follow
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	var names []string
+	err := filepath.WalkDir(dir, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && filename == dir {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil {
+			return err
+		}
+		if name := filepath.ToSlash(rel); !kept[name] {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
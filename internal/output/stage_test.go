@@ -0,0 +1,198 @@
+package output
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"feed/archive"
+	"feed/config"
+	"feed/feeds"
+	"feed/internal/testutil"
+)
+
+// failing is a writer that writes a file, then fails.
+type failing struct{}
+
+func (failing) Write(ctx context.Context, set FeedSet) error {
+	if err := set.WriteJSON("feed.json", "partial"); err != nil {
+		return err
+	}
+	return errors.New("failed")
+}
+
+// listFiles returns the files under dir as sorted slash-separated paths.
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	names, err := stale(dir, nil)
+	if err != nil {
+		t.Fatalf("Failed to list %s: %v", dir, err)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(`"old"`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRun_PrunesStaleFiles(t *testing.T) {
+	set := newSet(t, feeds.FeedItem{ID: "x:1", Platform: "x", Username: "godev", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)})
+	writeFiles(t, set.Dir, "feed.json", "feed_page_7.json", "platforms/myspace.json")
+
+	writers, err := New(&config.Config{})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if err := Run(context.Background(), writers, set); err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+
+	expected := []string{"authors.json", "feed.json", "manifest.json", "meta.json", "sources.json"}
+	if got := listFiles(t, set.Dir); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected files %v, got %v", expected, got)
+	}
+	var manifest Manifest
+	testutil.ReadJSON(t, set.Dir, ManifestFile, &manifest)
+	if len(manifest.Files) != 4 || manifest.Files[0].Path != "authors.json" || manifest.Files[3].Path != "sources.json" {
+		t.Errorf("Expected the manifest to list the other files, got %+v", manifest.Files)
+	}
+
+	// The output is a symlink to the stage, and no other stage is left behind
+	entries, _ := os.ReadDir(filepath.Dir(set.Dir))
+	if len(entries) != 2 {
+		t.Errorf("Expected only the output symlink and its stage, got %d entries", len(entries))
+	}
+	if target, err := os.Readlink(set.Dir); err != nil || !strings.HasPrefix(target, stagePrefix(set.Dir)) {
+		t.Errorf("Expected the output to link to a stage, got %q (%v)", target, err)
+	}
+}
+
+func TestRun_SwapsSymlink(t *testing.T) {
+	set := newSet(t, feeds.FeedItem{ID: "x:1", Platform: "x", Username: "godev", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)})
+	writers, err := New(&config.Config{})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	var stages []string
+	for run := 0; run < 2; run++ {
+		set = NewFeedSet(set.Dir, set.Items, set.Directory, set.Generated)
+		if err := Run(context.Background(), writers, set); err != nil {
+			t.Fatalf("Run %d returned an error: %v", run, err)
+		}
+		target, err := os.Readlink(set.Dir)
+		if err != nil {
+			t.Fatalf("Expected the output to be a symlink after run %d: %v", run, err)
+		}
+		stages = append(stages, target)
+	}
+	if stages[0] == stages[1] {
+		t.Errorf("Expected each run to link a new stage, got %v twice", stages[0])
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(set.Dir), stages[0])); !os.IsNotExist(err) {
+		t.Errorf("Expected the previous stage to be removed, got %v", err)
+	}
+	if got := listFiles(t, set.Dir); len(got) != 5 {
+		t.Errorf("Expected the files of the last run, got %v", got)
+	}
+}
+
+func TestNewStage_RestoresOutput(t *testing.T) {
+	parent := t.TempDir()
+	target := filepath.Join(parent, "output")
+	// A run stopped after moving the output aside
+	writeFiles(t, filepath.Join(parent, ".output-123.old"), "feed.json")
+
+	s, err := newStage(target)
+	if err != nil {
+		t.Fatalf("newStage returned an error: %v", err)
+	}
+	defer s.discard()
+	if got := listFiles(t, target); !reflect.DeepEqual(got, []string{"feed.json"}) {
+		t.Errorf("Expected the output to be restored, got %v", got)
+	}
+}
+
+func TestRun_KeepsOutputOnError(t *testing.T) {
+	set := newSet(t)
+	writeFiles(t, set.Dir, "feed.json", "feed_page_7.json")
+
+	if err := Run(context.Background(), []Writer{failing{}}, set); err == nil {
+		t.Fatalf("Expected Run to return the writer's error")
+	}
+
+	expected := []string{"feed.json", "feed_page_7.json"}
+	if got := listFiles(t, set.Dir); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the previous files %v, got %v", expected, got)
+	}
+	var feed string
+	testutil.ReadJSON(t, set.Dir, "feed.json", &feed)
+	if feed != "old" {
+		t.Errorf("Expected the previous feed.json, got %q", feed)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(set.Dir))
+	if len(entries) != 1 {
+		t.Errorf("Expected the staging directory to be removed, got %d entries", len(entries))
+	}
+}
+
+func TestRun_CarriesOverArchive(t *testing.T) {
+	set := newSet(t, feeds.FeedItem{ID: "x:1", Platform: "x", Username: "godev", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)})
+	// An ended month that none of the fetched items belong to
+	filename := filepath.Join(set.Dir, "archive", "2024", "06.json")
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"schema_version":2,"year":2024,"month":6,"immutable":true,"items":[]}`)
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A compressed copy from a run that had compression turned on
+	writeFiles(t, set.Dir, "archive/2024/06.json.gz")
+
+	writers, err := New(&config.Config{Outputs: []config.OutputConfig{{Type: "archive"}}})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if err := Run(context.Background(), writers, set); err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+
+	var carried archive.Month
+	testutil.ReadJSON(t, set.Dir, "archive/2024/06.json", &carried)
+	if carried.Year != 2024 || carried.Month != 6 || !carried.Immutable {
+		t.Errorf("Expected the ended month to be carried over, got %+v", carried)
+	}
+	var index archive.Index
+	testutil.ReadJSON(t, set.Dir, archive.IndexFile, &index)
+	if len(index.Months) != 2 || index.Months[1].File != "archive/2024/06.json" {
+		t.Errorf("Expected the index to list both months, got %+v", index.Months)
+	}
+	var manifest Manifest
+	testutil.ReadJSON(t, set.Dir, ManifestFile, &manifest)
+	found := false
+	for _, entry := range manifest.Files {
+		found = found || entry.Path == "archive/2024/06.json"
+	}
+	if !found {
+		t.Errorf("Expected the carried-over month in the manifest, got %+v", manifest.Files)
+	}
+	if _, err := os.Stat(filepath.Join(set.Dir, "archive", "2024", "06.json.gz")); !os.IsNotExist(err) {
+		t.Errorf("Expected the stale compressed copy to be pruned, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+
+	"feed/config"
+	"feed/feeds"
+)
+
+// TagIndexEntry is an entry of tags.json.
+type TagIndexEntry struct {
+	Tag   string `json:"tag"`
+	Slug  string `json:"slug"`
+	Count int    `json:"count"`
+	Feed  string `json:"feed"` // Path to the newest page of the tag's feed
+}
+
+// tagWriter writes a feed per tag into tags/ and the tag index, tags.json.
+type tagWriter struct {
+	options pageOptions
+}
+
+func newTagWriter(cfg *config.Config, entry config.OutputConfig) (Writer, error) {
+	options, err := newPageOptions(cfg, entry, cfg.Excerpts.TagFeeds)
+	if err != nil {
+		return nil, err
+	}
+	return &tagWriter{options: options}, nil
+}
+
+func (w *tagWriter) Write(ctx context.Context, set FeedSet) error {
+	log.Println("Generating tag feeds...")
+	set.Meta.TagFeeds = make(map[string]string)
+
+	tagIndex := []TagIndexEntry{}
+	for _, tag := range feeds.GroupByTag(excerpts(set.Items, w.options.Excerpts)) {
+		// Pages are named as in the main and platform feeds: TAG.json when
+		// not paginated, otherwise TAG_page_N.json, with the head copied to
+		// TAG.json in stable mode. The pages are always objects.
+		pageName := func(pageNum int) string {
+			if !w.options.paginated() {
+				return tag.Slug + ".json"
+			}
+			return fmt.Sprintf("%s_page_%d.json", tag.Slug, pageNum)
+		}
+		var feedPath string
+		for i, paginatedFeed := range paginate(tag.Items, w.options.PageSize, w.options.pagination, set.Directory, pageName) {
+			filenames := []string{pageName(paginatedFeed.CurrentPage)}
+			if i == 0 && w.options.stable() {
+				filenames = append(filenames, fmt.Sprintf("%s.json", tag.Slug))
+			}
+			for _, filename := range filenames {
+				if err := set.WriteJSON(path.Join("tags", filename), paginatedFeed); err != nil {
+					return fmt.Errorf("error writing feed for tag %s page %d: %w", tag.Name, paginatedFeed.CurrentPage, err)
+				}
+			}
+			if i == 0 { // Link to the newest page, as for platform feeds
+				feedPath = filepath.Join("tags", filenames[len(filenames)-1])
+			}
+		}
+		set.Meta.TagFeeds[tag.Name] = feedPath
+		tagIndex = append(tagIndex, TagIndexEntry{Tag: tag.Name, Slug: tag.Slug, Count: len(tag.Items), Feed: feedPath})
+	}
+
+	if err := set.WriteJSON("tags.json", tagIndex); err != nil {
+		return fmt.Errorf("error writing tags.json: %w", err)
+	}
+	set.Meta.Tags = "tags.json"
+	log.Printf("Generated feeds for %d tags.", len(tagIndex))
+	return nil
+}
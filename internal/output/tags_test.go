@@ -0,0 +1,71 @@
+package output
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"feed/config"
+	"feed/feeds"
+	"feed/internal/testutil"
+)
+
+func TestTagWriter(t *testing.T) {
+	set := newSet(t,
+		feeds.FeedItem{ID: "x:3", Platform: "x", Tags: []string{"go"}, Timestamp: time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)},
+		feeds.FeedItem{ID: "blog:2", Platform: "blog", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)},
+		feeds.FeedItem{ID: "x:1", Platform: "x", Tags: []string{"go"}, Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+	)
+	w, err := newTagWriter(&config.Config{}, config.OutputConfig{Type: "tags"})
+	if err != nil {
+		t.Fatalf("newTagWriter returned an error: %v", err)
+	}
+	if err := w.Write(context.Background(), set); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	var index []TagIndexEntry
+	testutil.ReadJSON(t, set.Dir, "tags.json", &index)
+	if len(index) != 1 || index[0].Slug != "go" || index[0].Count != 2 || index[0].Feed != filepath.Join("tags", "go.json") {
+		t.Errorf("Unexpected tag index: %+v", index)
+	}
+
+	var feed PaginatedFeed
+	testutil.ReadJSON(t, set.Dir, "tags/go.json", &feed)
+	if len(feed.Items) != 2 || feed.TotalPages != 1 || feed.NextPage != nil {
+		t.Errorf("Expected both tagged items on one page, got %+v", feed)
+	}
+	if set.Meta.Tags != "tags.json" || set.Meta.TagFeeds["go"] != filepath.Join("tags", "go.json") {
+		t.Errorf("Expected the tag outputs in meta, got %+v", set.Meta)
+	}
+}
+
+func TestTagWriter_Paginated(t *testing.T) {
+	set := newSet(t,
+		feeds.FeedItem{ID: "x:3", Platform: "x", Tags: []string{"go"}, Timestamp: time.Date(2025, 1, 3, 10, 0, 0, 0, time.UTC)},
+		feeds.FeedItem{ID: "blog:2", Platform: "blog", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)},
+		feeds.FeedItem{ID: "x:1", Platform: "x", Tags: []string{"go"}, Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+	)
+	w, err := newTagWriter(&config.Config{PageSize: 2}, config.OutputConfig{Type: "tags"})
+	if err != nil {
+		t.Fatalf("newTagWriter returned an error: %v", err)
+	}
+	if err := w.Write(context.Background(), set); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	// Named like the platform feeds: the newest page is TAG_page_1.json
+	var feed PaginatedFeed
+	testutil.ReadJSON(t, set.Dir, "tags/go_page_1.json", &feed)
+	if len(feed.Items) != 2 || feed.CurrentPage != 1 {
+		t.Errorf("Expected both tagged items on page 1, got %+v", feed)
+	}
+	if _, err := os.Stat(filepath.Join(set.Dir, "tags", "go.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected no tags/go.json in newest mode, got %v", err)
+	}
+	if set.Meta.TagFeeds["go"] != filepath.Join("tags", "go_page_1.json") {
+		t.Errorf("Expected the newest page in meta, got %v", set.Meta.TagFeeds)
+	}
+}
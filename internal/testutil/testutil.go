@@ -0,0 +1,30 @@
+// Package testutil holds helpers shared by the tests of the packages that
+// write files.
+package testutil
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ReadFile returns the content of the file name under dir, failing the test
+// if it was not written.
+func ReadFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("Expected %s to be written: %v", name, err)
+	}
+	return string(data)
+}
+
+// ReadJSON decodes the file name under dir into v, failing the test if it
+// was not written or is not valid JSON.
+func ReadJSON(t *testing.T, dir, name string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(ReadFile(t, dir, name)), v); err != nil {
+		t.Fatalf("Failed to parse %s: %v", name, err)
+	}
+}
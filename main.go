@@ -1,14 +1,11 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
 	"sort"
-	"strings"
 	"time"
 
 	"feed/config"
@@ -22,26 +19,13 @@ import (
 	"feed/feeds/strava"
 	"feed/feeds/threads"
 	"feed/feeds/x"
+	"feed/httpcache"
+	"feed/internal/output"
+	"feed/normalize"
+	"feed/opml"
+	"feed/preview"
 )
 
-// PaginatedFeed represents the structure for a paginated JSON output.
-type PaginatedFeed struct {
-	Items       []feeds.FeedItem `json:"items"`
-	CurrentPage int              `json:"current_page"`
-	TotalPages  int              `json:"total_pages"`
-	NextPage    *string          `json:"next_page,omitempty"`
-	PrevPage    *string          `json:"prev_page,omitempty"`
-}
-
-// MetaData represents the overall metadata for the generated feeds.
-type MetaData struct {
-	TotalItems      int               `json:"total_items"`
-	TotalPages      int               `json:"total_pages,omitempty"`
-	MainFeedPages   []string          `json:"main_feed_pages,omitempty"`
-	PlatformFeeds   map[string]string `json:"platform_feeds,omitempty"`
-	IndividualItems string            `json:"individual_items_directory,omitempty"`
-}
-
 func main() {
 	// Dummy usage of time to ensure import is not removed by linter
 	_ = time.Now()
@@ -57,6 +41,47 @@ func main() {
 		log.Println("Successfully loaded config.yaml.example.")
 	}
 
+	// All outbound fetches share one client so conditional GET validators
+	// persist across runs; each consumer caches in a namespace of its own
+	httpClient := httpcache.New(cfg.HTTPCacheDir)
+	if cfg.HTTPCacheDir != "" {
+		log.Printf("Using HTTP cache directory: %s", cfg.HTTPCacheDir)
+	}
+	normalizer := normalize.New(httpClient.HTTP)
+
+	// Subcommands operate on the configuration instead of generating feeds
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export-opml":
+			var path string
+			if len(os.Args) > 2 {
+				path = os.Args[2]
+			}
+			if err := exportOPML(rssSources(cfg.Feeds.RSS, httpClient.WithNamespace("opml")), path); err != nil {
+				log.Fatalf("Error exporting OPML: %v", err)
+			}
+			return
+		default:
+			log.Fatalf("Error: Unknown command %q (available commands: export-opml)", os.Args[1])
+		}
+	}
+
+	dateFallback, err := feeds.ParseDateFallback(cfg.DateFallback)
+	if err != nil {
+		log.Fatalf("Error: Invalid date_fallback setting: %v", err)
+	}
+	log.Printf("Items with unparseable dates will use the '%s' date fallback policy.", dateFallback)
+
+	writers, err := output.New(cfg)
+	if err != nil {
+		log.Fatalf("Error: Invalid outputs setting: %v", err)
+	}
+
+	engagementWeights, err := feeds.ParseEngagementWeights(cfg.EngagementWeights)
+	if err != nil {
+		log.Fatalf("Error: Invalid engagement_weights setting: %v", err)
+	}
+
 	var allFeedItems []feeds.FeedItem
 
 	// Initialize and fetch data from enabled feeds
@@ -67,7 +92,7 @@ func main() {
 		if err != nil {
 			log.Printf("Error fetching LinkedIn feed: %v", err)
 		} else {
-			allFeedItems = append(allFeedItems, items...)
+			allFeedItems = append(allFeedItems, normalizer.Apply(items, normalizeSteps(cfg.Normalize, cfg.Feeds.LinkedIn.Normalize))...)
 			log.Printf("Fetched %d items from LinkedIn.", len(items))
 		}
 	}
@@ -79,7 +104,7 @@ func main() {
 		if err != nil {
 			log.Printf("Error fetching Threads feed: %v", err)
 		} else {
-			allFeedItems = append(allFeedItems, items...)
+			allFeedItems = append(allFeedItems, normalizer.Apply(items, normalizeSteps(cfg.Normalize, cfg.Feeds.Threads.Normalize))...)
 			log.Printf("Fetched %d items from Threads.", len(items))
 		}
 	}
@@ -91,7 +116,7 @@ func main() {
 		if err != nil {
 			log.Printf("Error fetching X feed: %v", err)
 		} else {
-			allFeedItems = append(allFeedItems, items...)
+			allFeedItems = append(allFeedItems, normalizer.Apply(items, normalizeSteps(cfg.Normalize, cfg.Feeds.X.Normalize))...)
 			log.Printf("Fetched %d items from X.", len(items))
 		}
 	}
@@ -103,7 +128,7 @@ func main() {
 		if err != nil {
 			log.Printf("Error fetching Instagram feed: %v", err)
 		} else {
-			allFeedItems = append(allFeedItems, items...)
+			allFeedItems = append(allFeedItems, normalizer.Apply(items, normalizeSteps(cfg.Normalize, cfg.Feeds.Instagram.Normalize))...)
 			log.Printf("Fetched %d items from Instagram.", len(items))
 		}
 	}
@@ -115,7 +140,7 @@ func main() {
 		if err != nil {
 			log.Printf("Error fetching Reddit feed: %v", err)
 		} else {
-			allFeedItems = append(allFeedItems, items...)
+			allFeedItems = append(allFeedItems, normalizer.Apply(items, normalizeSteps(cfg.Normalize, cfg.Feeds.Reddit.Normalize))...)
 			log.Printf("Fetched %d items from Reddit.", len(items))
 		}
 	}
@@ -127,7 +152,7 @@ func main() {
 		if err != nil {
 			log.Printf("Error fetching Strava feed: %v", err)
 		} else {
-			allFeedItems = append(allFeedItems, items...)
+			allFeedItems = append(allFeedItems, normalizer.Apply(items, normalizeSteps(cfg.Normalize, cfg.Feeds.Strava.Normalize))...)
 			log.Printf("Fetched %d items from Strava.", len(items))
 		}
 	}
@@ -139,7 +164,7 @@ func main() {
 		if err != nil {
 			log.Printf("Error fetching Goodreads feed: %v", err)
 		} else {
-			allFeedItems = append(allFeedItems, items...)
+			allFeedItems = append(allFeedItems, normalizer.Apply(items, normalizeSteps(cfg.Normalize, cfg.Feeds.Goodreads.Normalize))...)
 			log.Printf("Fetched %d items from Goodreads.", len(items))
 		}
 	}
@@ -151,25 +176,53 @@ func main() {
 		if err != nil {
 			log.Printf("Error fetching Credly feed: %v", err)
 		} else {
-			allFeedItems = append(allFeedItems, items...)
+			allFeedItems = append(allFeedItems, normalizer.Apply(items, normalizeSteps(cfg.Normalize, cfg.Feeds.Credly.Normalize))...)
 			log.Printf("Fetched %d items from Credly.", len(items))
 		}
 	}
 
 	if cfg.Feeds.RSS.Enabled {
 		log.Println("Fetching RSS feeds...")
-		for _, url := range cfg.Feeds.RSS.URLs {
-			rssFeed := rss.NewRSSFeed(url)
+		rssClient := httpClient.WithNamespace("rss")
+		for _, source := range rssSources(cfg.Feeds.RSS, httpClient.WithNamespace("opml")) {
+			if !source.IsEnabled() {
+				log.Printf("Skipping disabled RSS feed: %s", source.URL)
+				continue
+			}
+			rssFeed := rss.NewRSSFeed(source.URL)
+			rssFeed.DateFallback = dateFallback
+			rssFeed.Client = rssClient
+			rssFeed.Name = source.Name
+			rssFeed.Tags = source.Tags
+			rssFeed.Author = source.Author
+			rssFeed.Limit = source.Limit
+			rssFeed.Header = source.Header()
+			if source.Platform != "" {
+				rssFeed.Platform = source.Platform
+			}
 			items, err := rssFeed.Fetch()
 			if err != nil {
-				log.Printf("Error fetching RSS feed from %s: %v", url, err)
+				log.Printf("Error fetching RSS feed from %s: %v", source.URL, err)
 			} else {
-				allFeedItems = append(allFeedItems, items...)
-				log.Printf("Fetched %d items from RSS feed: %s.", len(items), url)
+				allFeedItems = append(allFeedItems, normalizer.Apply(items, normalizeSteps(cfg.Normalize, cfg.Feeds.RSS.Normalize, source.Normalize))...)
+				log.Printf("Fetched %d items from RSS feed: %s.", len(items), source.URL)
 			}
 		}
 	}
 
+	// Recompute interactions with the configured weights
+	feeds.ScoreEngagement(allFeedItems, engagementWeights)
+
+	if cfg.FoldThreads {
+		before := len(allFeedItems)
+		allFeedItems = feeds.FoldThreads(allFeedItems)
+		log.Printf("Folded self-reply threads: %d items became %d.", before, len(allFeedItems))
+	}
+
+	// Find hashtags, mentions, links and cashtags; hashtags also become tags.
+	// This runs after folding so offsets match the thread's joined text.
+	feeds.ExtractEntities(allFeedItems)
+
 	// Sort feed items by timestamp in descending order
 	sort.Slice(allFeedItems, func(i, j int) bool {
 		return allFeedItems[i].Timestamp.After(allFeedItems[j].Timestamp)
@@ -181,209 +234,99 @@ func main() {
 		allFeedItems = allFeedItems[:cfg.OutputLimit]
 	}
 
-	// Create output directories if they don't exist
-	outputDir := "output"
-	itemsDir := filepath.Join(outputDir, "items")
-	platformsDir := filepath.Join(outputDir, "platforms")
-
-	if _, err := os.Stat(outputDir); os.IsNotExist(err) {
-		err = os.MkdirAll(outputDir, 0755)
-		if err != nil {
-			log.Fatalf("Error creating output directory: %v", err)
-		}
+	// Unfurl links only for the items that made it past the limit
+	if cfg.LinkPreviews.Enabled {
+		log.Println("Fetching link previews...")
+		unfurler := preview.New(httpClient.WithNamespace("preview"), cfg.LinkPreviews.Timeout, cfg.LinkPreviews.Denylist)
+		unfurler.Apply(allFeedItems)
 	}
 
-	var meta MetaData
-	meta.TotalItems = len(allFeedItems)
-	meta.PlatformFeeds = make(map[string]string)
-
-	// Generate individual item files if enabled
-	if cfg.GenerateIndividualItemFiles {
-		log.Println("Generating individual item files...")
-		if _, err := os.Stat(itemsDir); os.IsNotExist(err) {
-			err = os.MkdirAll(itemsDir, 0755)
-			if err != nil {
-				log.Fatalf("Error creating individual items directory: %v", err)
-			}
-		}
-		meta.IndividualItems = "items/"
-
-		for i, item := range allFeedItems {
-			// Use a combination of timestamp and index for a unique filename
-			filename := fmt.Sprintf("%s_%d.json", item.Timestamp.Format("20060102150405"), i)
-			itemFilePath := filepath.Join(itemsDir, filename)
-			item.Permalink = filepath.Join("items", filename) // Set permalink for the item
-
-			jsonData, err := json.MarshalIndent(item, "", "  ")
-			if err != nil {
-				log.Printf("Error marshalling individual item JSON for %s: %v", filename, err)
-				continue
-			}
+	// Normalize authors and sources into tables that items reference by ID
+	directory := feeds.BuildDirectory(allFeedItems)
 
-			err = ioutil.WriteFile(itemFilePath, jsonData, 0644)
-			if err != nil {
-				log.Printf("Error writing individual item JSON to file %s: %v", itemFilePath, err)
-			}
-			allFeedItems[i] = item // Update the item in the slice with the permalink
-		}
-		log.Printf("Generated %d individual item files.", len(allFeedItems))
+	set := output.NewFeedSet("output", allFeedItems, directory, time.Now())
+	set.Minify = cfg.MinifyJSON
+	set.Compression = cfg.Compression
+	if err := output.Run(context.Background(), writers, set); err != nil {
+		log.Fatalf("Error writing output: %v", err)
 	}
+}
 
-	// Generate platform-specific feeds if enabled
-	if cfg.GeneratePlatformFeeds {
-		log.Println("Generating platform-specific feeds...")
-		if _, err := os.Stat(platformsDir); os.IsNotExist(err) {
-			err = os.MkdirAll(platformsDir, 0755)
-			if err != nil {
-				log.Fatalf("Error creating platforms directory: %v", err)
-			}
-		}
-
-		platformItems := make(map[string][]feeds.FeedItem)
-		for _, item := range allFeedItems {
-			platformItems[item.Platform] = append(platformItems[item.Platform], item)
-		}
-
-		for platform, items := range platformItems {
-			log.Printf("Generating feed for platform: %s with %d items...", platform, len(items))
-			platformSlug := strings.ToLower(platform) // Create a URL-friendly slug
-
-			if cfg.PageSize > 1 {
-				// Paginate platform feed
-				totalPages := (len(items) + cfg.PageSize - 1) / cfg.PageSize
-				for pageNum := 0; pageNum < totalPages; pageNum++ {
-					start := pageNum * cfg.PageSize
-					end := (pageNum + 1) * cfg.PageSize
-					if end > len(items) {
-						end = len(items)
-					}
-					pageItems := items[start:end]
-
-					nextPage := (*string)(nil)
-					if pageNum < totalPages-1 {
-						nextPageFile := fmt.Sprintf("%s_page_%d.json", platformSlug, pageNum+2)
-						nextPage = &nextPageFile
-					}
-
-					prevPage := (*string)(nil)
-					if pageNum > 0 {
-						prevPageFile := fmt.Sprintf("%s_page_%d.json", platformSlug, pageNum)
-						prevPage = &prevPageFile
-					}
-
-					paginatedFeed := PaginatedFeed{
-						Items:       pageItems,
-						CurrentPage: pageNum + 1,
-						TotalPages:  totalPages,
-						NextPage:    nextPage,
-						PrevPage:    prevPage,
-					}
-
-					filename := fmt.Sprintf("%s_page_%d.json", platformSlug, pageNum+1)
-					filePath := filepath.Join(platformsDir, filename)
-					jsonData, err := json.MarshalIndent(paginatedFeed, "", "  ")
-					if err != nil {
-						log.Printf("Error marshalling JSON for platform %s page %d: %v", platform, pageNum+1, err)
-						continue
-					}
-					err = ioutil.WriteFile(filePath, jsonData, 0644)
-					if err != nil {
-						log.Printf("Error writing JSON for platform %s page %d to file: %v", platform, pageNum+1, err)
-					}
-					if pageNum == 0 { // Store link to the first page of each platform feed
-						meta.PlatformFeeds[platform] = filepath.Join("platforms", filename)
-					}
-				}
-			} else {
-				// Single file for platform feed
-				filename := fmt.Sprintf("%s.json", platformSlug)
-				filePath := filepath.Join(platformsDir, filename)
-				jsonData, err := json.MarshalIndent(items, "", "  ")
-				if err != nil {
-					log.Printf("Error marshalling JSON for platform %s: %v", platform, err)
-					continue
-				}
-				err = ioutil.WriteFile(filePath, jsonData, 0644)
-				if err != nil {
-					log.Printf("Error writing JSON for platform %s to file: %v", platform, err)
-				}
-				meta.PlatformFeeds[platform] = filepath.Join("platforms", filename)
-			}
+// normalizeSteps resolves the normalization steps for a source from its
+// config levels, outermost first, on top of normalize.DefaultSteps.
+func normalizeSteps(levels ...config.NormalizeConfig) normalize.Steps {
+	var merged config.NormalizeConfig
+	for _, level := range levels {
+		merged = merged.Merge(level)
+	}
+	steps := normalize.DefaultSteps()
+	for _, step := range []struct {
+		enabled *bool
+		value   *bool
+	}{
+		{&steps.SanitizeHTML, merged.SanitizeHTML},
+		{&steps.PlainText, merged.PlainText},
+		{&steps.DecodeEntities, merged.DecodeEntities},
+		{&steps.CollapseWhitespace, merged.CollapseWhitespace},
+		{&steps.ExpandShorteners, merged.ExpandShorteners},
+		{&steps.StripTracking, merged.StripTracking},
+	} {
+		if step.value != nil {
+			*step.enabled = *step.value
 		}
-		log.Println("Finished generating platform-specific feeds.")
 	}
+	return steps
+}
 
-	// Generate main aggregated feed (with pagination if configured)
-	log.Println("Generating main aggregated feed...")
-	if cfg.PageSize > 1 {
-		totalPages := (len(allFeedItems) + cfg.PageSize - 1) / cfg.PageSize
-		meta.TotalPages = totalPages
-		for pageNum := 0; pageNum < totalPages; pageNum++ {
-			start := pageNum * cfg.PageSize
-			end := (pageNum + 1) * cfg.PageSize
-			if end > len(allFeedItems) {
-				end = len(allFeedItems)
-			}
-			pageItems := allFeedItems[start:end]
-
-			nextPage := (*string)(nil)
-			if pageNum < totalPages-1 {
-				nextPageFile := fmt.Sprintf("feed_page_%d.json", pageNum+2)
-				nextPage = &nextPageFile
-			}
-
-			prevPage := (*string)(nil)
-			if pageNum > 0 {
-				prevPageFile := fmt.Sprintf("feed_page_%d.json", pageNum)
-				prevPage = &prevPageFile
-			}
-
-			paginatedFeed := PaginatedFeed{
-				Items:       pageItems,
-				CurrentPage: pageNum + 1,
-				TotalPages:  totalPages,
-				NextPage:    nextPage,
-				PrevPage:    prevPage,
-			}
+// rssSources returns the RSS sources listed in the config followed by those
+// imported from its OPML file. A URL listed in both keeps its config entry.
+func rssSources(rssCfg config.RSSConfig, client *httpcache.Client) []config.RSSSource {
+	sources := rssCfg.Sources()
+	if rssCfg.OPML == "" {
+		return sources
+	}
 
-			filename := fmt.Sprintf("feed_page_%d.json", pageNum+1)
-			outputFilePath := filepath.Join(outputDir, filename)
-			jsonData, err := json.MarshalIndent(paginatedFeed, "", "  ")
-			if err != nil {
-				log.Fatalf("Error marshalling JSON for main feed page %d: %v", pageNum+1, err)
-			}
-			err = ioutil.WriteFile(outputFilePath, jsonData, 0644)
-			if err != nil {
-				log.Fatalf("Error writing JSON for main feed page %d to file: %v", pageNum+1, err)
-			}
-			meta.MainFeedPages = append(meta.MainFeedPages, filepath.Join(outputDir, filename))
-		}
-		log.Printf("Successfully aggregated %d feed items into %d paginated files.", len(allFeedItems), totalPages)
-	} else {
-		// Write aggregated data to a single JSON file
-		outputFilePath := filepath.Join(outputDir, "feed.json")
-		jsonData, err := json.MarshalIndent(allFeedItems, "", "  ")
-		if err != nil {
-			log.Fatalf("Error marshalling JSON: %v", err)
-		}
+	doc, err := opml.Load(rssCfg.OPML, client)
+	if err != nil {
+		log.Printf("Error loading OPML subscriptions from %s: %v", rssCfg.OPML, err)
+		return sources
+	}
 
-		err = ioutil.WriteFile(outputFilePath, jsonData, 0644)
-		if err != nil {
-			log.Fatalf("Error writing JSON to file: %v", err)
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		seen[source.URL] = true
+	}
+	imported := 0
+	for _, sub := range doc.Subscriptions() {
+		if seen[sub.XMLURL] {
+			continue
 		}
-		meta.MainFeedPages = []string{filepath.Join(outputDir, "feed.json")}
-		log.Printf("Successfully aggregated %d feed items to %s", len(allFeedItems), outputFilePath)
+		seen[sub.XMLURL] = true
+		sources = append(sources, config.RSSSource{URL: sub.XMLURL, Name: sub.Title, Tags: sub.Categories})
+		imported++
 	}
+	log.Printf("Imported %d RSS sources from OPML: %s.", imported, rssCfg.OPML)
+	return sources
+}
 
-	// Write metadata to meta.json
-	metaFilePath := filepath.Join(outputDir, "meta.json")
-	metaJsonData, err := json.MarshalIndent(meta, "", "  ")
-	if err != nil {
-		log.Fatalf("Error marshalling metadata JSON: %v", err)
+// exportOPML writes sources as an OPML subscription list to path, or to
+// standard output if path is empty.
+func exportOPML(sources []config.RSSSource, path string) error {
+	subs := make([]opml.Subscription, 0, len(sources))
+	for _, source := range sources {
+		subs = append(subs, opml.Subscription{Title: source.Name, XMLURL: source.URL, Categories: source.Tags})
 	}
-	err = ioutil.WriteFile(metaFilePath, metaJsonData, 0644)
+	data, err := opml.New("Feed subscriptions", subs).Marshal()
 	if err != nil {
-		log.Fatalf("Error writing metadata JSON to file: %v", err)
+		return err
+	}
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return err
 	}
-	log.Printf("Successfully generated metadata to %s", metaFilePath)
+	log.Printf("Exported %d RSS sources to %s", len(sources), path)
+	return nil
 }
@@ -0,0 +1,156 @@
+// Package normalize cleans up fetched items before they are written out:
+// it sanitizes HTML, derives plain text, decodes entities, tidies whitespace,
+// expands link shorteners and strips tracking parameters. Every step can be
+// switched on or off per source.
+package normalize
+
+import (
+	"html"
+	"net/http"
+	"strings"
+
+	"feed/feeds"
+)
+
+// Steps selects which normalization steps run.
+type Steps struct {
+	// SanitizeHTML reduces ContentHTML to an allowlist of elements and
+	// attributes, dropping scripts, styles, embeds and event handlers.
+	SanitizeHTML bool
+	// PlainText derives ContentText from ContentHTML, and strips markup that
+	// leaked into ContentText or Summary.
+	PlainText bool
+	// DecodeEntities decodes HTML entities such as &amp; in the title and
+	// plain-text fields.
+	DecodeEntities bool
+	// CollapseWhitespace trims lines, collapses runs of spaces and keeps at
+	// most one blank line between paragraphs.
+	CollapseWhitespace bool
+	// ExpandShorteners replaces links from known shorteners (t.co, lnkd.in,
+	// ...) with their destination. It makes a request per distinct link.
+	ExpandShorteners bool
+	// StripTracking removes tracking query parameters (utm_*, fbclid, ...)
+	// from links.
+	StripTracking bool
+}
+
+// DefaultSteps enables every step except ExpandShorteners, which needs
+// network access.
+func DefaultSteps() Steps {
+	return Steps{
+		SanitizeHTML:       true,
+		PlainText:          true,
+		DecodeEntities:     true,
+		CollapseWhitespace: true,
+		StripTracking:      true,
+	}
+}
+
+// Normalizer applies Steps to items. Expanded links are remembered for the
+// lifetime of the Normalizer, so a link shared by several items is only
+// resolved once.
+type Normalizer struct {
+	expander *expander
+}
+
+// New creates a Normalizer that resolves shortened links with client.
+func New(client *http.Client) *Normalizer {
+	return &Normalizer{expander: newExpander(client)}
+}
+
+// Apply normalizes items in place according to steps and returns them.
+func (n *Normalizer) Apply(items []feeds.FeedItem, steps Steps) []feeds.FeedItem {
+	for i := range items {
+		n.apply(&items[i], steps)
+	}
+	return items
+}
+
+func (n *Normalizer) apply(item *feeds.FeedItem, steps Steps) {
+	rewrite := func(link string) string {
+		if steps.ExpandShorteners {
+			link = n.expander.expand(link)
+		}
+		if steps.StripTracking {
+			link = StripTracking(link)
+		}
+		return link
+	}
+	rewriteLinks := steps.ExpandShorteners || steps.StripTracking
+
+	if item.ContentHTML != "" && (steps.SanitizeHTML || rewriteLinks) {
+		var rewriteURL func(string) string
+		if rewriteLinks {
+			rewriteURL = rewrite
+		}
+		item.ContentHTML = transformHTML(item.ContentHTML, steps.SanitizeHTML, rewriteURL)
+	}
+
+	// HTMLToText already decodes entities, so text it produced must not be
+	// decoded again: "&amp;lt;" would turn into "<" instead of "&lt;"
+	textDecoded, summaryDecoded := false, false
+	if steps.PlainText {
+		if item.ContentHTML != "" {
+			item.ContentText, textDecoded = feeds.HTMLToText(item.ContentHTML), true
+		} else if looksLikeHTML(item.ContentText) {
+			item.ContentText, textDecoded = feeds.HTMLToText(item.ContentText), true
+		}
+		if looksLikeHTML(item.Summary) {
+			item.Summary, summaryDecoded = feeds.HTMLToText(item.Summary), true
+		}
+	}
+
+	if rewriteLinks {
+		item.PostContent = feeds.ReplaceURLs(item.PostContent, rewrite)
+		item.ContentText = feeds.ReplaceURLs(item.ContentText, rewrite)
+		item.Summary = feeds.ReplaceURLs(item.Summary, rewrite)
+		if steps.StripTracking {
+			item.ProfileLink = StripTracking(item.ProfileLink)
+		}
+	}
+
+	if steps.DecodeEntities {
+		item.Title = html.UnescapeString(item.Title)
+		if !textDecoded {
+			item.ContentText = html.UnescapeString(item.ContentText)
+		}
+		if !summaryDecoded {
+			item.Summary = html.UnescapeString(item.Summary)
+		}
+	}
+
+	if steps.CollapseWhitespace {
+		item.Title = strings.Join(strings.Fields(item.Title), " ")
+		item.ContentText = CollapseWhitespace(item.ContentText)
+		item.Summary = CollapseWhitespace(item.Summary)
+	}
+}
+
+// CollapseWhitespace collapses runs of spaces and tabs within each line,
+// trims the lines, and keeps at most one blank line between paragraphs.
+func CollapseWhitespace(s string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// looksLikeHTML reports whether s appears to contain markup or entities.
+func looksLikeHTML(s string) bool {
+	if i := strings.IndexByte(s, '<'); i >= 0 && i+1 < len(s) {
+		next := s[i+1]
+		return next == '/' || next == '!' || (next|0x20 >= 'a' && next|0x20 <= 'z')
+	}
+	return false
+}
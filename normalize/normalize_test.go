@@ -0,0 +1,153 @@
+package normalize
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"feed/feeds"
+)
+
+func TestTransformHTML_Sanitize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`<p onclick="x()">Hi <b>there</b></p>`, `<p>Hi <b>there</b></p>`},
+		{`<div><span style="color:red">Text</span></div>`, `Text`},
+		{`<p>Before<script>alert("x")</script>After</p>`, `<p>BeforeAfter</p>`},
+		{`<iframe src="https://evil.example">inner</iframe><object><object>x</object>y</object>Kept`, `Kept`},
+		{`<a href="javascript:alert(1)" target="_blank">Link</a>`, `<a>Link</a>`},
+		{`<a href="/relative" title="T">Link</a>`, `<a href="/relative" title="T">Link</a>`},
+		{`<img src="data:image/png;base64,AAAA" alt="x"><img src="https://example.com/a.png" alt="A" onerror="x()">`, `<img alt="x"><img src="https://example.com/a.png" alt="A">`},
+		{`<!-- comment -->Fish &amp; chips`, `Fish &amp; chips`},
+	}
+
+	for _, tt := range tests {
+		if got := transformHTML(tt.input, true, nil); got != tt.expected {
+			t.Errorf("transformHTML(%q): Expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestStripTracking(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://example.com/post?utm_source=x&utm_medium=social", "https://example.com/post"},
+		{"https://example.com/post?id=7&fbclid=abc&UTM_Campaign=y#top", "https://example.com/post?id=7#top"},
+		{"https://example.com/post?id=7", "https://example.com/post?id=7"},
+		{"https://example.com/post", "https://example.com/post"},
+	}
+
+	for _, tt := range tests {
+		if got := StripTracking(tt.input); got != tt.expected {
+			t.Errorf("StripTracking(%q): Expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	input := "  First   line \n\n\n\t Second\tline\n \n"
+	expected := "First line\n\nSecond line"
+	if got := CollapseWhitespace(input); got != expected {
+		t.Errorf("CollapseWhitespace: Expected %q, got %q", expected, got)
+	}
+}
+
+func TestNormalizer_Apply(t *testing.T) {
+	items := []feeds.FeedItem{{
+		Title:       "  Fish &amp; chips  ",
+		PostContent: "Read https://example.com/a?utm_source=rss&id=1.",
+		ContentText: "Read   https://example.com/a?utm_source=rss&id=1.",
+		ContentHTML: `<p>Read <a href="https://example.com/a?utm_source=rss&amp;id=1">this</a></p><script>x()</script>`,
+		Summary:     "<p>Short &amp; sweet</p>",
+		ProfileLink: "https://example.com/a?fbclid=1",
+	}}
+
+	New(nil).Apply(items, DefaultSteps())
+
+	item := items[0]
+	if item.Title != "Fish & chips" {
+		t.Errorf("Title: Expected %q, got %q", "Fish & chips", item.Title)
+	}
+	if item.PostContent != "Read https://example.com/a?id=1." {
+		t.Errorf("PostContent: Expected tracking stripped, got %q", item.PostContent)
+	}
+	if item.ContentHTML != `<p>Read <a href="https://example.com/a?id=1">this</a></p>` {
+		t.Errorf("ContentHTML: Expected sanitized HTML, got %q", item.ContentHTML)
+	}
+	if item.ContentText != "Read this" {
+		t.Errorf("ContentText: Expected text derived from the HTML, got %q", item.ContentText)
+	}
+	if item.Summary != "Short & sweet" {
+		t.Errorf("Summary: Expected %q, got %q", "Short & sweet", item.Summary)
+	}
+	if item.ProfileLink != "https://example.com/a" {
+		t.Errorf("ProfileLink: Expected tracking stripped, got %q", item.ProfileLink)
+	}
+}
+
+func TestNormalizer_DecodeEntitiesOnce(t *testing.T) {
+	items := []feeds.FeedItem{
+		{ContentHTML: "<p>Use &amp;lt;p&amp;gt; tags</p>", Summary: "<p>&amp;amp; is an ampersand</p>"},
+		{ContentText: "Fish &amp; chips"},
+	}
+
+	New(nil).Apply(items, DefaultSteps())
+
+	// Text made from HTML is decoded by HTMLToText and must not be decoded again
+	if items[0].ContentText != "Use &lt;p&gt; tags" {
+		t.Errorf("ContentText: Expected %q, got %q", "Use &lt;p&gt; tags", items[0].ContentText)
+	}
+	if items[0].Summary != "&amp; is an ampersand" {
+		t.Errorf("Summary: Expected %q, got %q", "&amp; is an ampersand", items[0].Summary)
+	}
+	if items[1].ContentText != "Fish & chips" {
+		t.Errorf("ContentText: Expected plain text to be decoded, got %q", items[1].ContentText)
+	}
+}
+
+func TestNormalizer_ExpandShorteners(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected a HEAD request, got %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/abc":
+			http.Redirect(w, r, "/hop", http.StatusMovedPermanently)
+		case "/hop":
+			http.Redirect(w, r, "https://example.com/article?utm_medium=social", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	normalizer := New(server.Client())
+	normalizer.expander.hosts = map[string]bool{serverURL.Hostname(): true}
+
+	items := []feeds.FeedItem{
+		{ContentText: "New post: " + server.URL + "/abc"},
+		{ContentText: "Again " + server.URL + "/abc and " + server.URL + "/missing"},
+	}
+	steps := DefaultSteps()
+	steps.ExpandShorteners = true
+	normalizer.Apply(items, steps)
+
+	if items[0].ContentText != "New post: https://example.com/article" {
+		t.Errorf("Item 1 ContentText: Expected expanded link, got %q", items[0].ContentText)
+	}
+	expected := "Again https://example.com/article and " + server.URL + "/missing"
+	if items[1].ContentText != expected {
+		t.Errorf("Item 2 ContentText: Expected %q, got %q", expected, items[1].ContentText)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests with the shared link resolved once, got %d", requests)
+	}
+}
@@ -0,0 +1,143 @@
+package normalize
+
+import (
+	"net/url"
+	"strings"
+
+	"feed/feeds"
+
+	"golang.org/x/net/html"
+)
+
+// allowedElements maps the elements kept by SanitizeHTML to their allowed
+// attributes. Other elements are unwrapped: the tag goes, its text stays.
+var allowedElements = map[string]map[string]bool{
+	"a":          {"href": true, "title": true},
+	"abbr":       {"title": true},
+	"blockquote": {"cite": true},
+	"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"td":         {"colspan": true, "rowspan": true},
+	"th":         {"colspan": true, "rowspan": true},
+}
+
+// Elements kept without any attributes
+func init() {
+	for _, name := range []string{
+		"b", "br", "code", "del", "em", "figcaption", "figure", "h1", "h2", "h3", "h4", "h5", "h6",
+		"hr", "i", "li", "ol", "p", "pre", "s", "strong", "sub", "sup", "table", "tbody", "thead",
+		"tr", "u", "ul",
+	} {
+		allowedElements[name] = map[string]bool{}
+	}
+}
+
+// droppedElements are removed together with everything inside them.
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "svg": true, "math": true, "form": true,
+	"head": true, "title": true, "select": true, "textarea": true, "button": true,
+}
+
+// urlAttributes hold links, which are passed through the rewrite function and
+// must use a safe scheme.
+var urlAttributes = map[string]bool{"href": true, "src": true, "cite": true}
+
+// transformHTML re-renders an HTML fragment. With sanitize set, only
+// allowedElements and their attributes survive; with rewrite set, every link
+// in an attribute or in the text is passed through it.
+func transformHTML(fragment string, sanitize bool, rewrite func(string) string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(fragment))
+	var b strings.Builder
+	var dropping string // element whose content is being dropped
+	depth := 0          // nesting of dropping inside itself
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			return b.String()
+		}
+		token := tokenizer.Token()
+
+		if dropping != "" {
+			switch {
+			case tokenType == html.StartTagToken && token.Data == dropping:
+				depth++
+			case tokenType == html.EndTagToken && token.Data == dropping:
+				if depth--; depth == 0 {
+					dropping = ""
+				}
+			}
+			continue
+		}
+
+		switch tokenType {
+		case html.TextToken:
+			text := token.Data
+			if rewrite != nil {
+				text = feeds.ReplaceURLs(text, rewrite)
+			}
+			b.WriteString(html.EscapeString(text))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if sanitize && droppedElements[token.Data] {
+				if tokenType == html.StartTagToken {
+					dropping, depth = token.Data, 1
+				}
+				continue
+			}
+			allowed, ok := allowedElements[token.Data]
+			if sanitize && !ok {
+				continue
+			}
+			token.Attr = filterAttributes(token.Attr, sanitize, allowed, rewrite)
+			b.WriteString(token.String())
+		case html.EndTagToken:
+			if _, ok := allowedElements[token.Data]; sanitize && !ok {
+				continue
+			}
+			b.WriteString(token.String())
+		case html.CommentToken, html.DoctypeToken:
+			if !sanitize {
+				b.WriteString(token.String())
+			}
+		}
+	}
+}
+
+func filterAttributes(attrs []html.Attribute, sanitize bool, allowed map[string]bool, rewrite func(string) string) []html.Attribute {
+	kept := attrs[:0]
+	for _, attr := range attrs {
+		if sanitize && (attr.Namespace != "" || !allowed[attr.Key]) {
+			continue
+		}
+		if urlAttributes[attr.Key] {
+			if sanitize && !safeURL(attr.Val) {
+				continue
+			}
+			if rewrite != nil {
+				attr.Val = rewrite(attr.Val)
+			}
+		}
+		kept = append(kept, attr)
+	}
+	return kept
+}
+
+// safeURL accepts relative links and the http, https and mailto schemes,
+// rejecting javascript:, data: and the like.
+func safeURL(link string) bool {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+// SanitizeHTML reduces an HTML fragment to the allowed elements and
+// attributes, as the SanitizeHTML step does.
+func SanitizeHTML(fragment string) string {
+	return transformHTML(fragment, true, nil)
+}
@@ -0,0 +1,116 @@
+package normalize
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// trackingParams are query parameters added for analytics only.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "igshid": true,
+	"mc_cid": true, "mc_eid": true, "_hsenc": true, "_hsmi": true, "yclid": true,
+}
+
+// StripTracking removes utm_* and other tracking parameters from link. Links
+// that cannot be parsed, or carry no such parameters, are returned unchanged.
+func StripTracking(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.RawQuery == "" {
+		return link
+	}
+	params := strings.Split(u.RawQuery, "&")
+	kept := params[:0]
+	for _, param := range params {
+		name, _, _ := strings.Cut(param, "=")
+		if name, err := url.QueryUnescape(name); err == nil {
+			name = strings.ToLower(name)
+			if strings.HasPrefix(name, "utm_") || trackingParams[name] {
+				continue
+			}
+		}
+		kept = append(kept, param)
+	}
+	if len(kept) == len(params) {
+		return link
+	}
+	u.RawQuery = strings.Join(kept, "&")
+	return u.String()
+}
+
+// shorteners lists the hosts whose links ExpandShorteners resolves.
+var shorteners = map[string]bool{
+	"t.co": true, "lnkd.in": true, "bit.ly": true, "buff.ly": true, "ow.ly": true,
+	"tinyurl.com": true, "dlvr.it": true, "fb.me": true, "trib.al": true, "redd.it": true,
+}
+
+// maxRedirects bounds how many hops are followed when expanding a link.
+const maxRedirects = 5
+
+// expander resolves shortened links by following their redirects.
+type expander struct {
+	client *http.Client
+	hosts  map[string]bool
+
+	mu       sync.Mutex
+	resolved map[string]string
+}
+
+func newExpander(client *http.Client) *expander {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	// Redirects are followed by hand so that only the Location is needed and
+	// no destination page is ever downloaded.
+	noFollow := *client
+	noFollow.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &expander{client: &noFollow, hosts: shorteners, resolved: make(map[string]string)}
+}
+
+// expand returns the destination of a shortened link, or link itself if it
+// is not from a known shortener or cannot be resolved.
+func (e *expander) expand(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || !e.hosts[strings.ToLower(u.Hostname())] {
+		return link
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if resolved, ok := e.resolved[link]; ok {
+		return resolved
+	}
+	resolved := link
+	for hop := 0; hop < maxRedirects; hop++ {
+		next, ok := e.location(resolved)
+		if !ok {
+			break
+		}
+		resolved = next
+		if u, err := url.Parse(resolved); err != nil || !e.hosts[strings.ToLower(u.Hostname())] {
+			break
+		}
+	}
+	e.resolved[link] = resolved
+	return resolved
+}
+
+// location asks for link's redirect target with a HEAD request.
+func (e *expander) location(link string) (string, bool) {
+	resp, err := e.client.Head(link)
+	if err != nil {
+		return "", false
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return "", false
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return "", false
+	}
+	return location.String(), true
+}
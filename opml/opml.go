@@ -0,0 +1,203 @@
+// Package opml reads and writes OPML subscription lists, the format feed
+// readers use to import and export their RSS subscriptions.
+package opml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"feed/httpcache"
+)
+
+// Document is an OPML 1.0/2.0 document.
+type Document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+// Head holds the document metadata.
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// Body holds the top-level outlines.
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline is either a subscription (it has an xmlUrl) or a folder of further
+// outlines.
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Category string    `xml:"category,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Subscription is a single feed listed in a Document.
+type Subscription struct {
+	Title   string
+	XMLURL  string
+	HTMLURL string
+	// Categories combines the outline's category attribute with the names of
+	// the folders it is nested in.
+	Categories []string
+}
+
+// Parse decodes an OPML document.
+func Parse(data []byte) (*Document, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	var doc Document
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OPML: %w", err)
+	}
+	return &doc, nil
+}
+
+// Load reads an OPML document from a local path or an http(s) URL, fetching
+// URLs through client.
+func Load(location string, client *httpcache.Client) (*Document, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		data, err := os.ReadFile(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OPML file %s: %w", location, err)
+		}
+		return Parse(data)
+	}
+
+	resp, err := client.Get(location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OPML from %s: %w", location, err)
+	}
+	if resp.Cached {
+		var doc Document
+		if err = resp.Decode(&doc); err == nil {
+			return &doc, nil
+		}
+		log.Printf("Warning: Could not decode cached OPML for %s, fetching it again: %v", location, err)
+		if resp, err = client.Refresh(location, nil); err != nil {
+			return nil, fmt.Errorf("failed to fetch OPML from %s: %w", location, err)
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OPML from %s, status code: %d", location, resp.StatusCode)
+	}
+
+	doc, err := Parse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Store(resp, doc); err != nil {
+		log.Printf("Warning: Could not cache OPML from %s: %v", location, err)
+	}
+	return doc, nil
+}
+
+// Subscriptions flattens the document's outline tree into its feeds.
+func (d *Document) Subscriptions() []Subscription {
+	var subs []Subscription
+	collect(d.Body.Outlines, nil, &subs)
+	return subs
+}
+
+func collect(outlines []Outline, folders []string, subs *[]Subscription) {
+	for _, outline := range outlines {
+		title := outline.Title
+		if title == "" {
+			title = outline.Text
+		}
+		if outline.XMLURL == "" {
+			// A folder: its name becomes a category of everything inside it
+			nested := folders
+			if title != "" {
+				nested = append(append([]string(nil), folders...), title)
+			}
+			collect(outline.Outlines, nested, subs)
+			continue
+		}
+		*subs = append(*subs, Subscription{
+			Title:      title,
+			XMLURL:     strings.TrimSpace(outline.XMLURL),
+			HTMLURL:    outline.HTMLURL,
+			Categories: categories(folders, outline.Category),
+		})
+	}
+}
+
+// categories merges folder names with an OPML category attribute, which is a
+// comma-separated list of slash-delimited paths such as "/Tech/Go,/News".
+// Every path segment becomes its own category.
+func categories(folders []string, attr string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	add := func(category string) {
+		category = strings.TrimSpace(category)
+		if category != "" && !seen[strings.ToLower(category)] {
+			seen[strings.ToLower(category)] = true
+			result = append(result, category)
+		}
+	}
+	for _, folder := range folders {
+		add(folder)
+	}
+	for _, path := range strings.Split(attr, ",") {
+		for _, segment := range strings.Split(path, "/") {
+			add(segment)
+		}
+	}
+	return result
+}
+
+// New builds a document listing subs as a flat list of RSS outlines.
+func New(title string, subs []Subscription) *Document {
+	doc := &Document{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, sub := range subs {
+		text := sub.Title
+		if text == "" {
+			text = sub.XMLURL
+		}
+		var category []string
+		for _, c := range sub.Categories {
+			category = append(category, "/"+c)
+		}
+		doc.Body.Outlines = append(doc.Body.Outlines, Outline{
+			Text:     text,
+			Title:    sub.Title,
+			Type:     "rss",
+			XMLURL:   sub.XMLURL,
+			HTMLURL:  sub.HTMLURL,
+			Category: strings.Join(category, ","),
+		})
+	}
+	return doc
+}
+
+// Marshal encodes the document as indented XML with a declaration.
+func (d *Document) Marshal() ([]byte, error) {
+	data, err := xml.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
@@ -0,0 +1,105 @@
+package opml
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"feed/httpcache"
+)
+
+const mockOPML = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>My subscriptions</title></head>
+  <body>
+    <outline text="Tech">
+      <outline text="Go Blog" title="The Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.xml" htmlUrl="https://go.dev/blog" category="/Programming/Go"/>
+      <outline text="Lab Notes" type="rss" xmlUrl=" http://testlab.example.org/index.rdf "/>
+    </outline>
+    <outline text="News" type="rss" xmlUrl="https://news.example.com/rss" category="World,/Tech"/>
+  </body>
+</opml>`
+
+func TestDocument_Subscriptions(t *testing.T) {
+	doc, err := Parse([]byte(mockOPML))
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+
+	subs := doc.Subscriptions()
+	expected := []Subscription{
+		{Title: "The Go Blog", XMLURL: "https://go.dev/blog/feed.xml", HTMLURL: "https://go.dev/blog", Categories: []string{"Tech", "Programming", "Go"}},
+		{Title: "Lab Notes", XMLURL: "http://testlab.example.org/index.rdf", Categories: []string{"Tech"}},
+		{Title: "News", XMLURL: "https://news.example.com/rss", Categories: []string{"World", "Tech"}},
+	}
+	if !reflect.DeepEqual(subs, expected) {
+		t.Errorf("Subscriptions: Expected %+v, got %+v", expected, subs)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subscriptions.opml")
+	if err := os.WriteFile(path, []byte(mockOPML), 0644); err != nil {
+		t.Fatalf("Failed to write OPML file: %v", err)
+	}
+	doc, err := Load(path, httpcache.New(""))
+	if err != nil {
+		t.Fatalf("Load from file returned an error: %v", err)
+	}
+	if len(doc.Subscriptions()) != 3 {
+		t.Errorf("Expected 3 subscriptions from file, got %d", len(doc.Subscriptions()))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/x-opml")
+		w.Write([]byte(mockOPML))
+	}))
+	defer server.Close()
+
+	doc, err = Load(server.URL, httpcache.New(""))
+	if err != nil {
+		t.Fatalf("Load from URL returned an error: %v", err)
+	}
+	if len(doc.Subscriptions()) != 3 {
+		t.Errorf("Expected 3 subscriptions from URL, got %d", len(doc.Subscriptions()))
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.opml"), httpcache.New("")); err == nil {
+		t.Errorf("Expected an error for a missing file, got nil")
+	}
+}
+
+func TestNew_RoundTrip(t *testing.T) {
+	subs := []Subscription{
+		{Title: "The Go Blog", XMLURL: "https://go.dev/blog/feed.xml", Categories: []string{"Programming", "Go"}},
+		{XMLURL: "https://news.example.com/rss"},
+	}
+
+	data, err := New("Exported", subs).Marshal()
+	if err != nil {
+		t.Fatalf("Marshal returned an error: %v", err)
+	}
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse of exported OPML returned an error: %v", err)
+	}
+	if doc.Head.Title != "Exported" {
+		t.Errorf("Expected title 'Exported', got '%s'", doc.Head.Title)
+	}
+
+	got := doc.Subscriptions()
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 subscriptions, got %d", len(got))
+	}
+	if !reflect.DeepEqual(got[0], subs[0]) {
+		t.Errorf("Subscription 1: Expected %+v, got %+v", subs[0], got[0])
+	}
+	// Untitled sources fall back to their URL as outline text
+	if got[1].Title != "https://news.example.com/rss" {
+		t.Errorf("Subscription 2 Title: Expected URL, got '%s'", got[1].Title)
+	}
+}
@@ -0,0 +1,409 @@
+// Package preview unfurls links: for each item it fetches the first external
+// link and reads the page's OpenGraph, Twitter Card and oEmbed metadata into
+// a feeds.LinkPreview, so that link-only posts have something to show.
+package preview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"feed/feeds"
+	"feed/httpcache"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// DefaultTimeout bounds each request made while unfurling a link.
+const DefaultTimeout = 5 * time.Second
+
+// MaxPageSize bounds the body read for a page or an oEmbed response.
+const MaxPageSize = 2 << 20
+
+// maxRedirects is how many redirects a fetch follows, as net/http does.
+const maxRedirects = 10
+
+var errPrivateAddress = errors.New("refusing to connect to a private address")
+
+// Unfurler attaches link previews to items. Pages are fetched through an
+// httpcache.Client, so with a cache directory previews are revalidated rather
+// than re-downloaded; within a run each link is fetched at most once.
+type Unfurler struct {
+	client   *httpcache.Client
+	denylist []string
+
+	previews map[string]*feeds.LinkPreview // nil for links without metadata
+}
+
+// New creates an Unfurler that fetches through client, giving up on a
+// request after timeout (DefaultTimeout if zero). Links to a domain in
+// denylist, or any of its subdomains, are never fetched, not even through a
+// redirect. Since the links come from other people's posts, connections to
+// loopback, private and link-local addresses are refused as well.
+func New(client *httpcache.Client, timeout time.Duration, denylist []string) *Unfurler {
+	return newUnfurler(client, timeout, denylist, publicIP)
+}
+
+// newUnfurler is New with the check for the addresses that may be dialled.
+func newUnfurler(client *httpcache.Client, timeout time.Duration, denylist []string, allowed func(net.IP) bool) *Unfurler {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	var domains []string
+	for _, domain := range denylist {
+		if domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), "."); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	u := &Unfurler{denylist: domains, previews: make(map[string]*feeds.LinkPreview)}
+
+	withTimeout := *client
+	withTimeout.MaxBodySize = MaxPageSize
+	httpClient := http.Client{}
+	if client.HTTP != nil {
+		httpClient = *client.HTTP
+	}
+	httpClient.Timeout = timeout
+	httpClient.Transport = guardedTransport(httpClient.Transport, allowed)
+	httpClient.CheckRedirect = u.checkRedirect
+	withTimeout.HTTP = &httpClient
+	u.client = &withTimeout
+	return u
+}
+
+// guardedTransport copies base, or the default transport, so that it only
+// dials addresses allowed accepts. The address is checked after the name is
+// resolved, so a public name pointing at a private address is refused too.
+// Proxies are not used, as they would dial on the transport's behalf.
+func guardedTransport(base http.RoundTripper, allowed func(net.IP) bool) *http.Transport {
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.Proxy = nil
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return fmt.Errorf("%w: %s", errPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// publicIP reports whether ip may be dialled: loopback, private, link-local,
+// multicast and unspecified addresses may not.
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// checkRedirect applies the denylist to every hop of a redirect chain.
+func (u *Unfurler) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing to follow a redirect to %s", req.URL)
+	}
+	if u.denied(req.URL.Hostname()) {
+		return fmt.Errorf("refusing to follow a redirect to denylisted %s", req.URL.Hostname())
+	}
+	return nil
+}
+
+// Apply sets LinkPreview on every item whose first external link has
+// metadata, and returns items. Links are taken from the item's Entities, so
+// feeds.ExtractEntities should run first.
+func (u *Unfurler) Apply(items []feeds.FeedItem) []feeds.FeedItem {
+	for i := range items {
+		link := u.firstExternalLink(items[i])
+		if link == "" {
+			continue
+		}
+		if preview := u.preview(link); preview != nil {
+			p := *preview
+			items[i].LinkPreview = &p
+		}
+	}
+	return items
+}
+
+// firstExternalLink returns the item's first link that points away from its
+// own platform and is not denylisted, or "".
+func (u *Unfurler) firstExternalLink(item feeds.FeedItem) string {
+	own := map[string]bool{}
+	for _, link := range []string{item.ProfileLink, sourceHome(item)} {
+		if parsed, err := url.Parse(link); err == nil && parsed.Hostname() != "" {
+			own[hostKey(parsed.Hostname())] = true
+		}
+	}
+	for _, entity := range item.Entities {
+		if entity.Kind != feeds.EntityURL {
+			continue
+		}
+		parsed, err := url.Parse(entity.Value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" || own[hostKey(parsed.Hostname())] || u.denied(parsed.Hostname()) {
+			continue
+		}
+		return entity.Value
+	}
+	return ""
+}
+
+func sourceHome(item feeds.FeedItem) string {
+	if item.Source != nil {
+		return item.Source.HomeURL
+	}
+	return ""
+}
+
+// hostKey lower-cases host and drops a leading "www." so that example.com
+// and www.example.com compare equal.
+func hostKey(host string) string {
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
+
+// denied reports whether host is a denylisted domain or a subdomain of one.
+func (u *Unfurler) denied(host string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range u.denylist {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// preview returns the metadata for link, fetching it on first use.
+func (u *Unfurler) preview(link string) *feeds.LinkPreview {
+	if preview, ok := u.previews[link]; ok {
+		return preview
+	}
+	preview, err := u.fetch(link)
+	if err != nil {
+		log.Printf("Warning: Could not unfurl %s: %v", link, err)
+	}
+	if preview != nil && *preview == (feeds.LinkPreview{URL: link}) {
+		preview = nil
+	}
+	u.previews[link] = preview
+	return preview
+}
+
+func (u *Unfurler) fetch(link string) (*feeds.LinkPreview, error) {
+	header := http.Header{"Accept": {"text/html,application/xhtml+xml"}}
+	resp, err := u.client.Get(link, header)
+	if err != nil {
+		return nil, err
+	}
+	preview := feeds.LinkPreview{URL: link}
+	if resp.Cached {
+		if err := resp.Decode(&preview); err == nil {
+			preview.URL = link
+			return &preview, nil
+		}
+		// An entry that no longer decodes counts as missing
+		if resp, err = u.client.Refresh(link, header); err != nil {
+			return nil, err
+		}
+		preview = feeds.LinkPreview{URL: link}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		// Images, PDFs and the like carry no page metadata
+		return nil, nil
+	}
+	page, err := parsePage(resp.Body, contentType, link)
+	if err != nil {
+		return nil, err
+	}
+	preview = page.preview(link)
+	if (preview.Title == "" || preview.Image == "") && page.oEmbed != "" {
+		if embed, err := u.fetchOEmbed(page.oEmbed); err != nil {
+			log.Printf("Warning: Could not fetch oEmbed data for %s: %v", link, err)
+		} else {
+			preview.Title = firstNonEmpty(preview.Title, embed.Title)
+			preview.Image = firstNonEmpty(preview.Image, embed.ThumbnailURL)
+			preview.SiteName = firstNonEmpty(preview.SiteName, embed.ProviderName)
+		}
+	}
+	if err := u.client.Store(resp, preview); err != nil {
+		log.Printf("Warning: Could not cache link preview for %s: %v", link, err)
+	}
+	return &preview, nil
+}
+
+// oEmbed holds the oEmbed response fields used for previews.
+type oEmbed struct {
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+func (u *Unfurler) fetchOEmbed(endpoint string) (*oEmbed, error) {
+	// The endpoint comes from the page, so it gets the same checks as links
+	if parsed, err := url.Parse(endpoint); err != nil || u.denied(parsed.Hostname()) {
+		return nil, fmt.Errorf("refusing to fetch oEmbed endpoint %s", endpoint)
+	}
+	header := http.Header{"Accept": {"application/json"}}
+	resp, err := u.client.Get(endpoint, header)
+	if err != nil {
+		return nil, err
+	}
+	var embed oEmbed
+	if resp.Cached {
+		err := resp.Decode(&embed)
+		if err == nil {
+			return &embed, nil
+		}
+		// An entry that no longer decodes counts as missing
+		log.Printf("Warning: Cached oEmbed data for %s is unusable, fetching it again: %v", endpoint, err)
+		if resp, err = u.client.Refresh(endpoint, header); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(resp.Body, &embed); err != nil {
+		return nil, err
+	}
+	if err := u.client.Store(resp, embed); err != nil {
+		log.Printf("Warning: Could not cache oEmbed data for %s: %v", endpoint, err)
+	}
+	return &embed, nil
+}
+
+// page is the metadata found in an HTML document's head.
+type page struct {
+	meta   map[string]string // og:* and twitter:* properties, and description
+	title  string            // Text of the <title> element
+	oEmbed string            // JSON oEmbed endpoint advertised by the page
+}
+
+// parsePage reads the metadata in the head of an HTML document, resolving
+// links against base.
+func parsePage(body []byte, contentType, base string) (*page, error) {
+	reader, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &page{meta: make(map[string]string)}
+	tokenizer := html.NewTokenizer(reader)
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return p, nil
+		case html.TextToken:
+			if inTitle {
+				p.title += string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return p, nil
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "body":
+				return p, nil
+			case "title":
+				inTitle = true
+			case "meta":
+				key := strings.ToLower(firstNonEmpty(attr(token, "property"), attr(token, "name")))
+				if key == "description" || strings.HasPrefix(key, "og:") || strings.HasPrefix(key, "twitter:") {
+					if _, seen := p.meta[key]; !seen {
+						p.meta[key] = strings.TrimSpace(attr(token, "content"))
+					}
+				}
+			case "link":
+				if p.oEmbed == "" && strings.EqualFold(attr(token, "type"), "application/json+oembed") {
+					p.oEmbed = resolve(baseURL, attr(token, "href"))
+				}
+			}
+		}
+	}
+}
+
+// preview picks each field from OpenGraph, then Twitter Card, then plain
+// HTML metadata.
+func (p *page) preview(link string) feeds.LinkPreview {
+	base, _ := url.Parse(link)
+	image := firstNonEmpty(p.meta["og:image"], p.meta["og:image:url"], p.meta["og:image:secure_url"], p.meta["twitter:image"], p.meta["twitter:image:src"])
+	if image != "" {
+		image = resolve(base, image)
+	}
+	return feeds.LinkPreview{
+		URL:         link,
+		Title:       firstNonEmpty(p.meta["og:title"], p.meta["twitter:title"], strings.TrimSpace(p.title)),
+		Description: firstNonEmpty(p.meta["og:description"], p.meta["twitter:description"], p.meta["description"]),
+		Image:       image,
+		SiteName:    p.meta["og:site_name"],
+	}
+}
+
+func attr(token html.Token, name string) string {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// resolve makes ref absolute against base, returning "" for unsafe schemes.
+func resolve(base *url.URL, ref string) string {
+	parsed, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+	return parsed.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,184 @@
+package preview
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"feed/feeds"
+	"feed/httpcache"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html><head>
+<title>Fallback title</title>
+<meta property="og:title" content="Go Concurrency Patterns">
+<meta name="twitter:title" content="Twitter title">
+<meta name="twitter:description" content="Channels &amp; goroutines">
+<meta property="og:image" content="/images/gopher.png">
+<meta property="og:site_name" content="The Go Blog">
+</head><body><meta property="og:title" content="Ignored"></body></html>`
+
+func newServer(t *testing.T, requests map[string]int) *httptest.Server {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, articlePage)
+	})
+	mux.HandleFunc("/video", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><head><title>Video</title><link rel="alternate" type="application/json+oembed" href="%s/oembed"></head></html>`, server.URL)
+	})
+	mux.HandleFunc("/oembed", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=3600")
+		fmt.Fprint(w, `{"title": "oEmbed title", "provider_name": "VideoSite", "thumbnail_url": "https://img.example.com/thumb.jpg"}`)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+	})
+	mux.HandleFunc("/file.pdf", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		w.Header().Set("Content-Type", "application/pdf")
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// anyIP lets the tests fetch from their loopback servers.
+func anyIP(net.IP) bool { return true }
+
+func itemWithLinks(links ...string) feeds.FeedItem {
+	item := feeds.FeedItem{Platform: "x", ProfileLink: "https://x.com/godev"}
+	for _, link := range links {
+		item.Entities = append(item.Entities, feeds.Entity{Kind: feeds.EntityURL, Text: link, Value: link})
+	}
+	return item
+}
+
+func TestUnfurler_Apply(t *testing.T) {
+	requests := map[string]int{}
+	server := newServer(t, requests)
+
+	items := []feeds.FeedItem{
+		itemWithLinks("https://x.com/godev/status/1", server.URL+"/article"),
+		itemWithLinks(server.URL + "/article"),
+		itemWithLinks(server.URL + "/video"),
+		itemWithLinks(server.URL + "/file.pdf"),
+	}
+	newUnfurler(httpcache.New(""), 0, nil, anyIP).Apply(items)
+
+	expected := &feeds.LinkPreview{
+		URL:         server.URL + "/article",
+		Title:       "Go Concurrency Patterns",
+		Description: "Channels & goroutines",
+		Image:       server.URL + "/images/gopher.png",
+		SiteName:    "The Go Blog",
+	}
+	for i := 0; i < 2; i++ {
+		if items[i].LinkPreview == nil || *items[i].LinkPreview != *expected {
+			t.Errorf("Item %d: Expected preview %+v, got %+v", i, expected, items[i].LinkPreview)
+		}
+	}
+	if requests["/article"] != 1 {
+		t.Errorf("Expected the shared link to be fetched once, got %d requests", requests["/article"])
+	}
+
+	video := items[2].LinkPreview
+	if video == nil || video.Title != "Video" || video.Image != "https://img.example.com/thumb.jpg" || video.SiteName != "VideoSite" {
+		t.Errorf("Expected the oEmbed thumbnail and provider to fill in, got %+v", video)
+	}
+
+	if items[3].LinkPreview != nil {
+		t.Errorf("Expected no preview for a PDF, got %+v", items[3].LinkPreview)
+	}
+}
+
+func TestUnfurler_CachedOEmbed(t *testing.T) {
+	requests := map[string]int{}
+	server := newServer(t, requests)
+	client := httpcache.New(t.TempDir())
+
+	// The video page sends no caching headers, so each run fetches it again
+	// and reads the oEmbed data from the cache
+	var previews []*feeds.LinkPreview
+	for run := 0; run < 2; run++ {
+		items := []feeds.FeedItem{itemWithLinks(server.URL + "/video")}
+		newUnfurler(client, 0, nil, anyIP).Apply(items)
+		previews = append(previews, items[0].LinkPreview)
+	}
+
+	if requests["/video"] != 2 || requests["/oembed"] != 1 {
+		t.Errorf("Expected 2 page requests and 1 oEmbed request, got %v", requests)
+	}
+	for run, preview := range previews {
+		if preview == nil || preview.Image != "https://img.example.com/thumb.jpg" || preview.SiteName != "VideoSite" {
+			t.Errorf("Run %d: Expected the oEmbed thumbnail and provider, got %+v", run+1, preview)
+		}
+	}
+}
+
+func TestUnfurler_Denylist(t *testing.T) {
+	requests := map[string]int{}
+	server := newServer(t, requests)
+
+	items := []feeds.FeedItem{itemWithLinks("https://ads.tracker.example/landing", server.URL+"/article")}
+	unfurler := newUnfurler(httpcache.New(""), 0, []string{"TRACKER.example", "127.0.0.1"}, anyIP)
+	unfurler.Apply(items)
+
+	if items[0].LinkPreview != nil || len(requests) != 0 {
+		t.Errorf("Expected denylisted links to be skipped, got %+v after %d requests", items[0].LinkPreview, len(requests))
+	}
+}
+
+func TestUnfurler_PrivateAddresses(t *testing.T) {
+	requests := map[string]int{}
+	server := newServer(t, requests)
+
+	items := []feeds.FeedItem{itemWithLinks(server.URL + "/article")}
+	New(httpcache.New(""), 0, nil).Apply(items)
+
+	if items[0].LinkPreview != nil || len(requests) != 0 {
+		t.Errorf("Expected loopback links to be refused, got %+v after %d requests", items[0].LinkPreview, len(requests))
+	}
+	for ip, expected := range map[string]bool{
+		"93.184.216.34":   true,
+		"127.0.0.1":       false,
+		"10.0.0.1":        false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"::1":             false,
+		"fe80::1":         false,
+		"0.0.0.0":         false,
+	} {
+		if got := publicIP(net.ParseIP(ip)); got != expected {
+			t.Errorf("Expected publicIP(%s) to be %v, got %v", ip, expected, got)
+		}
+	}
+}
+
+func TestUnfurler_RedirectDenylist(t *testing.T) {
+	requests := map[string]int{}
+	server := newServer(t, requests)
+
+	// The link's host is allowed, but it redirects to a denylisted one
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	target := "http://localhost:" + port + "/article"
+	items := []feeds.FeedItem{itemWithLinks(server.URL + "/redirect?to=" + target)}
+	newUnfurler(httpcache.New(""), 0, []string{"localhost"}, anyIP).Apply(items)
+
+	if items[0].LinkPreview != nil || requests["/article"] != 0 {
+		t.Errorf("Expected the redirect to a denylisted host to be refused, got %+v after %d requests", items[0].LinkPreview, requests["/article"])
+	}
+	if requests["/redirect"] != 1 {
+		t.Errorf("Expected the link itself to be fetched once, got %d requests", requests["/redirect"])
+	}
+}
@@ -0,0 +1,289 @@
+package search
+
+// stem reduces an English word to its stem with the Porter stemming
+// algorithm (M.F. Porter, 1980), as in Porter's reference implementation,
+// which Lunr's English stemmer also follows. Words of two letters or fewer,
+// and words with characters other than a to z, are returned unchanged.
+func stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	z := &stemmer{b: []byte(word), k: len(word) - 1}
+	z.step1ab()
+	if z.k > 0 {
+		z.step1c()
+		z.step2()
+		z.step3()
+		z.step4()
+		z.step5()
+	}
+	return string(z.b[:z.k+1])
+}
+
+// stemmer holds a word being stemmed: b[:k+1] is the current word, and j
+// marks the end of the stem before a suffix found by ends.
+type stemmer struct {
+	b    []byte
+	k, j int
+}
+
+// cons reports whether b[i] is a consonant.
+func (z *stemmer) cons(i int) bool {
+	switch z.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !z.cons(i-1)
+	}
+	return true
+}
+
+// m measures the number of vowel-consonant sequences in b[:j+1]. Writing c
+// for a run of consonants and v for a run of vowels, every word is
+// [c](vc){m}[v].
+func (z *stemmer) m() int {
+	n, i := 0, 0
+	for ; ; i++ {
+		if i > z.j {
+			return n
+		}
+		if !z.cons(i) {
+			break
+		}
+	}
+	i++
+	for {
+		for ; ; i++ {
+			if i > z.j {
+				return n
+			}
+			if z.cons(i) {
+				break
+			}
+		}
+		i++
+		n++
+		for ; ; i++ {
+			if i > z.j {
+				return n
+			}
+			if !z.cons(i) {
+				break
+			}
+		}
+		i++
+	}
+}
+
+// vowelInStem reports whether b[:j+1] contains a vowel.
+func (z *stemmer) vowelInStem() bool {
+	for i := 0; i <= z.j; i++ {
+		if !z.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleC reports whether b[j-1:j+1] is a double consonant.
+func (z *stemmer) doubleC(j int) bool {
+	return j >= 1 && z.b[j] == z.b[j-1] && z.cons(j)
+}
+
+// cvc reports whether b[i-2:i+1] is consonant-vowel-consonant and the last
+// consonant is not w, x or y. It marks stems like hop(e) and fil(e).
+func (z *stemmer) cvc(i int) bool {
+	if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+		return false
+	}
+	switch z.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends reports whether the word ends with s, and sets j to the end of the
+// word without it.
+func (z *stemmer) ends(s string) bool {
+	if len(s) > z.k+1 || string(z.b[z.k+1-len(s):z.k+1]) != s {
+		return false
+	}
+	z.j = z.k - len(s)
+	return true
+}
+
+// setTo replaces the suffix after j with s.
+func (z *stemmer) setTo(s string) {
+	z.b = append(z.b[:z.j+1], s...)
+	z.k = z.j + len(s)
+}
+
+// replace replaces the suffix after j with s if the stem has m() > 0.
+func (z *stemmer) replace(s string) {
+	if z.m() > 0 {
+		z.setTo(s)
+	}
+}
+
+// replaceFirst replaces the first suffix in rules (pairs of suffix and
+// replacement) that the word ends with.
+func (z *stemmer) replaceFirst(rules ...string) {
+	for i := 0; i+1 < len(rules); i += 2 {
+		if z.ends(rules[i]) {
+			z.replace(rules[i+1])
+			return
+		}
+	}
+}
+
+// step1ab removes plurals and -ed or -ing: caresses → caress, ponies → poni,
+// cats → cat, agreed → agree, plastered → plaster, motoring → motor,
+// hopping → hop, filing → file.
+func (z *stemmer) step1ab() {
+	if z.b[z.k] == 's' {
+		if z.ends("sses") {
+			z.k -= 2
+		} else if z.ends("ies") {
+			z.setTo("i")
+		} else if z.b[z.k-1] != 's' {
+			z.k--
+		}
+	}
+	if z.ends("eed") {
+		if z.m() > 0 {
+			z.k--
+		}
+	} else if (z.ends("ed") || z.ends("ing")) && z.vowelInStem() {
+		z.k = z.j
+		if z.ends("at") {
+			z.setTo("ate")
+		} else if z.ends("bl") {
+			z.setTo("ble")
+		} else if z.ends("iz") {
+			z.setTo("ize")
+		} else if z.doubleC(z.k) {
+			z.k--
+			switch z.b[z.k] {
+			case 'l', 's', 'z':
+				z.k++
+			}
+		} else if z.m() == 1 && z.cvc(z.k) {
+			z.setTo("e")
+		}
+	}
+}
+
+// step1c turns a final y into i when there is another vowel in the stem.
+func (z *stemmer) step1c() {
+	if z.ends("y") && z.vowelInStem() {
+		z.b[z.k] = 'i'
+	}
+}
+
+// step2 maps double suffixes to single ones: -ization → -ize,
+// -ational → -ate, and so on, when the stem has m() > 0.
+func (z *stemmer) step2() {
+	switch z.b[z.k-1] {
+	case 'a':
+		z.replaceFirst("ational", "ate", "tional", "tion")
+	case 'c':
+		z.replaceFirst("enci", "ence", "anci", "ance")
+	case 'e':
+		z.replaceFirst("izer", "ize")
+	case 'l':
+		z.replaceFirst("bli", "ble", "alli", "al", "entli", "ent", "eli", "e", "ousli", "ous")
+	case 'o':
+		z.replaceFirst("ization", "ize", "ation", "ate", "ator", "ate")
+	case 's':
+		z.replaceFirst("alism", "al", "iveness", "ive", "fulness", "ful", "ousness", "ous")
+	case 't':
+		z.replaceFirst("aliti", "al", "iviti", "ive", "biliti", "ble")
+	case 'g':
+		z.replaceFirst("logi", "log")
+	}
+}
+
+// step3 handles -ic-, -full, -ness and the like.
+func (z *stemmer) step3() {
+	switch z.b[z.k] {
+	case 'e':
+		z.replaceFirst("icate", "ic", "ative", "", "alize", "al")
+	case 'i':
+		z.replaceFirst("iciti", "ic")
+	case 'l':
+		z.replaceFirst("ical", "ic", "ful", "")
+	case 's':
+		z.replaceFirst("ness", "")
+	}
+}
+
+// step4 removes -ant, -ence and the like when the stem has m() > 1.
+func (z *stemmer) step4() {
+	var suffixes []string
+	switch z.b[z.k-1] {
+	case 'a':
+		suffixes = []string{"al"}
+	case 'c':
+		suffixes = []string{"ance", "ence"}
+	case 'e':
+		suffixes = []string{"er"}
+	case 'i':
+		suffixes = []string{"ic"}
+	case 'l':
+		suffixes = []string{"able", "ible"}
+	case 'n':
+		suffixes = []string{"ant", "ement", "ment", "ent"}
+	case 'o':
+		if z.ends("ion") && z.j >= 0 && (z.b[z.j] == 's' || z.b[z.j] == 't') {
+			break
+		}
+		suffixes = []string{"ou"}
+	case 's':
+		suffixes = []string{"ism"}
+	case 't':
+		suffixes = []string{"ate", "iti"}
+	case 'u':
+		suffixes = []string{"ous"}
+	case 'v':
+		suffixes = []string{"ive"}
+	case 'z':
+		suffixes = []string{"ize"}
+	default:
+		return
+	}
+	if suffixes != nil {
+		found := false
+		for _, suffix := range suffixes {
+			if z.ends(suffix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+	}
+	if z.m() > 1 {
+		z.k = z.j
+	}
+}
+
+// step5 removes a final -e and changes -ll to -l when the stem has m() > 1.
+func (z *stemmer) step5() {
+	z.j = z.k
+	if z.b[z.k] == 'e' {
+		if a := z.m(); a > 1 || a == 1 && !z.cvc(z.k-1) {
+			z.k--
+		}
+	}
+	if z.b[z.k] == 'l' && z.doubleC(z.k) && z.m() > 1 {
+		z.k--
+	}
+}
@@ -0,0 +1,46 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	// Examples from Porter's paper and the reference vocabulary
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"ties":           "ti",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"conflated":      "conflat",
+		"troubled":       "troubl",
+		"sized":          "size",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"hissing":        "hiss",
+		"failing":        "fail",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"generalization": "gener",
+		"connections":    "connect",
+		"hopeful":        "hope",
+		"goodness":       "good",
+		"adjustable":     "adjust",
+		"adoption":       "adopt",
+		"controll":       "control",
+		"running":        "run",
+		"go":             "go",
+		"ies":            "i",
+		"café":           "café",
+		"go123":          "go123",
+	}
+	for word, expected := range tests {
+		if got := stem(word); got != expected {
+			t.Errorf("stem(%q): Expected %q, got %q", word, expected, got)
+		}
+	}
+}
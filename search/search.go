@@ -0,0 +1,294 @@
+// Package search writes an inverted index of the items, so that a static
+// page can search the feed without a server. The format is documented in
+// specs/README.md: a manifest (search/index.json), the documents
+// (search/documents_N.json) and the terms with their postings
+// (search/terms_N.json), sharded so that a client only loads what a query
+// needs.
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"feed/feeds"
+)
+
+// FormatVersion is the version of the index format, which changes whenever
+// a client would have to read the files differently.
+const FormatVersion = 1
+
+// Dir is the directory of the index, relative to the output directory.
+const Dir = "search"
+
+// ManifestFile is the path of the manifest, relative to the output directory.
+const ManifestFile = Dir + "/index.json"
+
+// Fields are the item fields that can be indexed, in the order of their
+// numbers in postings when all of them are indexed.
+var Fields = []string{"title", "content", "tags", "username"}
+
+// titleLength is the maximum length of a document title taken from the
+// item's content, when the item has no title.
+const titleLength = 80
+
+// language processes words for one language setting.
+type language struct {
+	stopWords map[string]bool
+	stem      func(string) string
+}
+
+var languages = map[string]language{
+	"english": {stopWords: englishStopWords, stem: stem},
+	"none":    {},
+}
+
+// Options configures the index.
+type Options struct {
+	// Fields lists the fields to index, out of Fields. Empty indexes all.
+	Fields []string
+	// Language is "english" (stop words and Porter stemming, the default)
+	// or "none" (words are only lower-cased).
+	Language string
+	// TermShards spreads the terms over this many files. Less than 1 means 1.
+	TermShards int
+	// DocumentsPerShard limits the number of documents per file. 0 puts all
+	// documents in one file.
+	DocumentsPerShard int
+}
+
+// Manifest is the content of search/index.json.
+type Manifest struct {
+	Version           int      `json:"version"` // FormatVersion
+	SchemaVersion     int      `json:"schema_version"`
+	Language          string   `json:"language"`
+	Fields            []string `json:"fields"` // Field numbers in postings index this list
+	DocumentCount     int      `json:"document_count"`
+	DocumentsPerShard int      `json:"documents_per_shard"` // Document d is in Documents[d / DocumentsPerShard]
+	Documents         []string `json:"documents"`           // Paths to the document shards
+	Terms             []string `json:"terms"`               // Paths to the term shards; see ShardOf
+}
+
+// Document describes an item in search results.
+type Document struct {
+	ID        string `json:"id,omitempty"`
+	Platform  string `json:"platform"`
+	Title     string `json:"title"` // The item's title, or the start of its content
+	Username  string `json:"username,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"` // RFC 3339
+	Permalink string `json:"permalink,omitempty"` // Individual item file, if generated
+}
+
+// Posting records that a term occurs in a field of a document:
+// [document, field, count].
+type Posting [3]int
+
+// Index writes search indexes.
+type Index struct {
+	fields   []string
+	language string
+	lang     language
+	shards   int
+	perShard int
+}
+
+// New validates options and returns an Index that applies them.
+func New(options Options) (*Index, error) {
+	x := &Index{
+		fields:   options.Fields,
+		language: strings.ToLower(strings.TrimSpace(options.Language)),
+		shards:   options.TermShards,
+		perShard: options.DocumentsPerShard,
+	}
+	if len(x.fields) == 0 {
+		x.fields = Fields
+	}
+	for _, field := range x.fields {
+		if !contains(Fields, field) {
+			return nil, fmt.Errorf("unknown search field %q (expected %s)", field, strings.Join(Fields, ", "))
+		}
+	}
+	if x.language == "" {
+		x.language = "english"
+	}
+	lang, ok := languages[x.language]
+	if !ok {
+		return nil, fmt.Errorf("unknown search language %q (expected english or none)", options.Language)
+	}
+	x.lang = lang
+	if x.shards < 1 {
+		x.shards = 1
+	}
+	if x.perShard < 0 {
+		x.perShard = 0
+	}
+	return x, nil
+}
+
+// ShardOf returns the term shard of term: the 32-bit FNV-1a hash of its
+// UTF-8 bytes, modulo the number of shards.
+func ShardOf(term string, shards int) int {
+	h := fnv.New32a()
+	h.Write([]byte(term))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// Terms splits text into the terms it is indexed under: lower-case words
+// without diacritics, stop words removed and stemmed as the language
+// setting says. Clients must process queries the same way.
+func (x *Index) Terms(text string) []string {
+	var terms []string
+	for _, word := range tokenize(text) {
+		if x.lang.stopWords[word] {
+			continue
+		}
+		if x.lang.stem != nil {
+			word = x.lang.stem(word)
+		}
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// Write indexes items and writes the index into outputDir/search. It
+// returns the paths written, relative to outputDir.
+func (x *Index) Write(outputDir string, items []feeds.FeedItem) ([]string, error) {
+	perShard := x.perShard
+	if perShard == 0 {
+		perShard = len(items)
+	}
+	manifest := Manifest{
+		Version:           FormatVersion,
+		SchemaVersion:     feeds.SchemaVersion,
+		Language:          x.language,
+		Fields:            x.fields,
+		DocumentCount:     len(items),
+		DocumentsPerShard: perShard,
+		Documents:         []string{},
+	}
+
+	documents := make([]Document, len(items))
+	terms := make([]map[string][]Posting, x.shards)
+	for i := range terms {
+		terms[i] = make(map[string][]Posting)
+	}
+	for d, item := range items {
+		documents[d] = document(item)
+		for f, field := range x.fields {
+			counts := make(map[string]int)
+			var order []string
+			for _, term := range x.Terms(fieldText(item, field)) {
+				if counts[term] == 0 {
+					order = append(order, term)
+				}
+				counts[term]++
+			}
+			for _, term := range order {
+				shard := terms[ShardOf(term, x.shards)]
+				shard[term] = append(shard[term], Posting{d, f, counts[term]})
+			}
+		}
+	}
+
+	var written []string
+	write := func(name string, v interface{}) error {
+		if err := writeJSON(filepath.Join(outputDir, filepath.FromSlash(name)), v); err != nil {
+			return err
+		}
+		written = append(written, name)
+		return nil
+	}
+	for start := 0; start < len(documents); start += perShard {
+		end := start + perShard
+		if end > len(documents) {
+			end = len(documents)
+		}
+		name := path.Join(Dir, fmt.Sprintf("documents_%d.json", start/perShard))
+		if err := write(name, documents[start:end]); err != nil {
+			return written, err
+		}
+		manifest.Documents = append(manifest.Documents, name)
+	}
+	for i, shard := range terms {
+		name := path.Join(Dir, fmt.Sprintf("terms_%d.json", i))
+		if err := write(name, shard); err != nil {
+			return written, err
+		}
+		manifest.Terms = append(manifest.Terms, name)
+	}
+	if err := write(ManifestFile, manifest); err != nil {
+		return written, err
+	}
+	return written, nil
+}
+
+// fieldText is the text of an item's field, including that of thread parts.
+func fieldText(item feeds.FeedItem, field string) string {
+	var texts []string
+	switch field {
+	case "title":
+		texts = append(texts, item.Title)
+	case "content":
+		texts = append(texts, item.Summary, content(item))
+		for _, part := range item.Parts {
+			texts = append(texts, content(part))
+		}
+	case "tags":
+		texts = append(texts, item.Tags...)
+	case "username":
+		texts = feeds.AddTags(texts, item.Username)
+		if item.Author != nil {
+			texts = feeds.AddTags(texts, item.Author.Handle, item.Author.DisplayName)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func content(item feeds.FeedItem) string {
+	if item.ContentText != "" {
+		return item.ContentText
+	}
+	return item.PostContent
+}
+
+func document(item feeds.FeedItem) Document {
+	doc := Document{
+		ID:        item.ID,
+		Platform:  item.Platform,
+		Title:     item.Title,
+		Username:  item.Username,
+		Permalink: filepath.ToSlash(item.Permalink),
+	}
+	if doc.Title == "" {
+		doc.Title, _ = feeds.Excerpt(strings.Join(strings.Fields(content(item)), " "), titleLength)
+	}
+	if !item.Timestamp.IsZero() {
+		doc.Timestamp = item.Timestamp.Format(time.RFC3339)
+	}
+	return doc
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(filename string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,135 @@
+package search
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"feed/feeds"
+	"feed/internal/testutil"
+)
+
+func TestIndex_Write(t *testing.T) {
+	dir := t.TempDir()
+	items := []feeds.FeedItem{
+		{
+			ID:          "x:1",
+			Platform:    "x",
+			ContentText: "Running the new Go release",
+			Username:    "GoDev",
+			Tags:        []string{"golang"},
+			Timestamp:   time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC),
+			Permalink:   "items/20250102100000_0.json",
+		},
+		{
+			ID:          "blog:2",
+			Platform:    "blog",
+			Title:       "Release notes",
+			ContentText: "Runners run. Go go go!",
+			Username:    "Jane",
+		},
+	}
+	x, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	written, err := x.Write(dir, items)
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+	expectedFiles := []string{"search/documents_0.json", "search/index.json", "search/terms_0.json"}
+	sort.Strings(written)
+	if !reflect.DeepEqual(written, expectedFiles) {
+		t.Errorf("Expected files %v, got %v", expectedFiles, written)
+	}
+
+	var manifest Manifest
+	testutil.ReadJSON(t, dir, "search/index.json", &manifest)
+	if manifest.Language != "english" || !reflect.DeepEqual(manifest.Fields, Fields) || manifest.DocumentCount != 2 || manifest.DocumentsPerShard != 2 {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+
+	var documents []Document
+	testutil.ReadJSON(t, dir, "search/documents_0.json", &documents)
+	expectedDocuments := []Document{
+		{ID: "x:1", Platform: "x", Title: "Running the new Go release", Username: "GoDev", Timestamp: "2025-01-02T10:00:00Z", Permalink: "items/20250102100000_0.json"},
+		{ID: "blog:2", Platform: "blog", Title: "Release notes", Username: "Jane"},
+	}
+	if !reflect.DeepEqual(documents, expectedDocuments) {
+		t.Errorf("Expected documents %+v, got %+v", expectedDocuments, documents)
+	}
+
+	var terms map[string][]Posting
+	testutil.ReadJSON(t, dir, "search/terms_0.json", &terms)
+	// "Running" and "run" share a stem; "runners" does not; "the" is a stop word
+	if expected := []Posting{{0, 1, 1}, {1, 1, 1}}; !reflect.DeepEqual(terms["run"], expected) {
+		t.Errorf("Expected postings %v for \"run\", got %v", expected, terms["run"])
+	}
+	if expected := []Posting{{0, 1, 1}, {1, 1, 3}}; !reflect.DeepEqual(terms["go"], expected) {
+		t.Errorf("Expected postings %v for \"go\", got %v", expected, terms["go"])
+	}
+	if expected := []Posting{{0, 1, 1}, {1, 0, 1}}; !reflect.DeepEqual(terms["releas"], expected) {
+		t.Errorf("Expected postings %v for \"releas\", got %v", expected, terms["releas"])
+	}
+	if expected := []Posting{{0, 2, 1}}; !reflect.DeepEqual(terms["golang"], expected) {
+		t.Errorf("Expected postings %v for \"golang\", got %v", expected, terms["golang"])
+	}
+	if _, ok := terms["the"]; ok {
+		t.Errorf("Expected stop words to be left out")
+	}
+}
+
+func TestIndex_Sharding(t *testing.T) {
+	dir := t.TempDir()
+	x, err := New(Options{Fields: []string{"content"}, Language: "none", TermShards: 3, DocumentsPerShard: 1})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	items := []feeds.FeedItem{
+		{ID: "x:1", ContentText: "Running the new Go release"},
+		{ID: "blog:2", ContentText: "Runners run. Go go go!"},
+	}
+	if _, err := x.Write(dir, items); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	var manifest Manifest
+	testutil.ReadJSON(t, dir, "search/index.json", &manifest)
+	if len(manifest.Documents) != 2 || len(manifest.Terms) != 3 {
+		t.Fatalf("Expected 2 document shards and 3 term shards, got %+v", manifest)
+	}
+
+	for _, term := range []string{"running", "the", "runners"} {
+		var terms map[string][]Posting
+		testutil.ReadJSON(t, dir, manifest.Terms[ShardOf(term, 3)], &terms)
+		if _, ok := terms[term]; !ok {
+			t.Errorf("Expected %q in its shard %s", term, manifest.Terms[ShardOf(term, 3)])
+		}
+	}
+
+	var documents []Document
+	testutil.ReadJSON(t, dir, manifest.Documents[1], &documents)
+	if len(documents) != 1 || documents[0].ID != "blog:2" {
+		t.Errorf("Expected the second document alone in the second shard, got %+v", documents)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New(Options{Fields: []string{"body"}}); err == nil {
+		t.Errorf("Expected an error for an unknown field")
+	}
+	if _, err := New(Options{Language: "klingon"}); err == nil {
+		t.Errorf("Expected an error for an unknown language")
+	}
+}
+
+func TestShardOf(t *testing.T) {
+	// FNV-1a of "go" is 0x4220774b (1109423947)
+	if shard := ShardOf("go", 10); shard != 7 {
+		t.Errorf("Expected shard 7, got %d", shard)
+	}
+	if shard := ShardOf("anything", 1); shard != 0 {
+		t.Errorf("Expected shard 0 with one shard, got %d", shard)
+	}
+}
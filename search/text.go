@@ -0,0 +1,58 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// tokenize splits text into lower-case words with diacritics removed: runs
+// of letters and digits, so "Café-Bar's" gives "cafe", "bar" and "s".
+func tokenize(text string) []string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range norm.NFD.String(strings.ToLower(text)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// A diacritic separated from its letter by NFD
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// englishStopWords are left out of the index. The list is Lunr's, so that a
+// client using Lunr's English pipeline on queries drops the same words.
+var englishStopWords = toSet(
+	"a", "able", "about", "across", "after", "all", "almost", "also", "am", "among",
+	"an", "and", "any", "are", "as", "at", "be", "because", "been", "but", "by",
+	"can", "cannot", "could", "dear", "did", "do", "does", "either", "else", "ever",
+	"every", "for", "from", "get", "got", "had", "has", "have", "he", "her", "hers",
+	"him", "his", "how", "however", "i", "if", "in", "into", "is", "it", "its",
+	"just", "least", "let", "like", "likely", "may", "me", "might", "most", "must",
+	"my", "neither", "no", "nor", "not", "of", "off", "often", "on", "only", "or",
+	"other", "our", "own", "rather", "said", "say", "says", "she", "should", "since",
+	"so", "some", "than", "that", "the", "their", "them", "then", "there", "these",
+	"they", "this", "tis", "to", "too", "twas", "us", "wants", "was", "we", "were",
+	"what", "when", "where", "which", "while", "who", "whom", "why", "will", "with",
+	"would", "yet", "you", "your",
+)
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,20 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := map[string][]string{
+		"Café-Bar's #GoLang 1.23": {"cafe", "bar", "s", "golang", "1", "23"},
+		"  ":                      nil,
+		"Ünïcödé naïve":           {"unicode", "naive"},
+		"東京 tower":                {"東京", "tower"},
+	}
+	for text, expected := range tests {
+		if got := tokenize(text); !reflect.DeepEqual(got, expected) {
+			t.Errorf("tokenize(%q): Expected %v, got %v", text, expected, got)
+		}
+	}
+}
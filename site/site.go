@@ -0,0 +1,433 @@
+// Package site renders the aggregated feed as a static HTML site next to the
+// JSON outputs: an index with paginated pages mirroring feed_page_N.json,
+// per-platform pages and per-item pages mirroring items/. Pages are rendered
+// with html/template from an embedded default theme, any of whose templates
+// can be overridden from a theme directory.
+package site
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"feed/feeds"
+	"feed/normalize"
+)
+
+//go:embed theme
+var defaultTheme embed.FS
+
+// Stylesheet is the file name of the theme's stylesheet, written to the
+// output directory.
+const Stylesheet = "style.css"
+
+// Options configures a Site.
+type Options struct {
+	Title       string
+	Description string
+	// BaseURL is the public URL of the output directory. It makes the
+	// OpenGraph og:url and og:image links absolute; without it they are
+	// left out or relative.
+	BaseURL string
+	// ThemeDir holds templates (*.html) that replace the default theme's
+	// templates of the same name, and optionally a style.css.
+	ThemeDir string
+	// PageSize paginates the index and platform pages like the JSON feeds.
+	// 0 or 1 puts every item on one page.
+	PageSize int
+	// PlatformPages writes a page per platform, like generate_platform_feeds.
+	PlatformPages bool
+	// Pagination numbers the pages like the JSON feeds' pagination setting.
+	Pagination feeds.Pagination
+	// Excerpt, if set, shortens items on the index and platform pages.
+	// Item pages always show the full item.
+	Excerpt func(feeds.FeedItem) feeds.FeedItem
+}
+
+// Site writes the HTML pages for a set of items.
+type Site struct {
+	options    Options
+	templates  *template.Template
+	stylesheet []byte
+}
+
+// New parses the default theme and the overrides in options.ThemeDir.
+func New(options Options) (*Site, error) {
+	templates, err := template.New("site").Funcs(funcs).ParseFS(defaultTheme, "theme/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse default theme: %w", err)
+	}
+	stylesheet, err := fs.ReadFile(defaultTheme, "theme/"+Stylesheet)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.ThemeDir != "" {
+		overrides, err := filepath.Glob(filepath.Join(options.ThemeDir, "*.html"))
+		if err != nil {
+			return nil, err
+		}
+		if len(overrides) > 0 {
+			if templates, err = templates.ParseFiles(overrides...); err != nil {
+				return nil, fmt.Errorf("failed to parse theme templates in %s: %w", options.ThemeDir, err)
+			}
+		}
+		if custom, err := os.ReadFile(filepath.Join(options.ThemeDir, Stylesheet)); err == nil {
+			stylesheet = custom
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return &Site{options: options, templates: templates, stylesheet: stylesheet}, nil
+}
+
+// entry is an item as seen by the templates.
+type entry struct {
+	feeds.FeedItem
+	Author *feeds.Author
+	Source *feeds.Source
+	Page   string // Link to the item's own page, if there is one
+}
+
+// link is a labelled link for navigation.
+type link struct {
+	Label string
+	URL   string
+}
+
+// page is the data every template is executed with.
+type page struct {
+	Site        Options
+	Title       string
+	Description string
+	Root        string // Relative path from the page to the output directory
+	URL         string // Absolute URL of the page, if BaseURL is set
+	Type        string // OpenGraph type: "website" or "article"
+	Image       string // OpenGraph image
+	Alternate   string // JSON file the page mirrors, relative to the page
+	Stylesheet  string
+	Platforms   []link
+
+	Entries    []entry // For list pages
+	Entry      *entry  // For item pages
+	Page       int
+	TotalPages int
+	Next       string
+	Prev       string
+
+	file string // Where the page is written, relative to the output directory
+}
+
+// Write renders the pages for items into outputDir, with authors and
+// sources looked up in directory. Items with a Permalink (see
+// generate_individual_item_files) get an item page next to their JSON file.
+// It returns the paths written, relative to outputDir.
+func (s *Site) Write(outputDir string, items []feeds.FeedItem, directory feeds.Directory) ([]string, error) {
+	var written []string
+	write := func(name, template string, data page) error {
+		if err := s.render(filepath.Join(outputDir, filepath.FromSlash(name)), template, data); err != nil {
+			return err
+		}
+		written = append(written, name)
+		return nil
+	}
+
+	platforms := s.platformLinks(items)
+	excerpt := s.options.Excerpt
+	if excerpt == nil {
+		excerpt = func(item feeds.FeedItem) feeds.FeedItem { return item }
+	}
+	listed := make([]feeds.FeedItem, len(items))
+	for i, item := range items {
+		listed[i] = excerpt(item)
+	}
+
+	// The index and its pages
+	for _, p := range s.paginate(listed, directory, "", "feed", "feed.json") {
+		p.Title = s.options.Title
+		p.Platforms = platforms
+		if err := write(p.file, "list", p); err != nil {
+			return written, err
+		}
+	}
+
+	// Platform pages
+	if s.options.PlatformPages {
+		byPlatform := make(map[string][]feeds.FeedItem)
+		labels := make(map[string]string)
+		for _, item := range listed {
+			slug := feeds.TagSlug(item.Platform)
+			if slug == "" {
+				continue
+			}
+			if _, ok := labels[slug]; !ok {
+				labels[slug] = item.Platform
+			}
+			byPlatform[slug] = append(byPlatform[slug], item)
+		}
+		for slug, platformItems := range byPlatform {
+			platform := labels[slug]
+			for _, p := range s.paginate(platformItems, directory, "../", slug, slug+".json") {
+				p.Title = fmt.Sprintf("%s: %s", s.options.Title, platform)
+				p.Platforms = platforms
+				if err := write(p.file, "list", p); err != nil {
+					return written, err
+				}
+			}
+		}
+	}
+
+	// Item pages
+	for _, item := range items {
+		if item.Permalink == "" {
+			continue
+		}
+		permalink := filepath.ToSlash(item.Permalink)
+		root := strings.Repeat("../", strings.Count(permalink, "/"))
+		e := s.entry(item, directory, root)
+		name := strings.TrimSuffix(permalink, ".json") + ".html"
+		p := page{
+			Site:        s.options,
+			Title:       itemTitle(item),
+			Description: description(item),
+			Root:        root,
+			URL:         s.absolute(name),
+			Type:        "article",
+			Image:       s.absolute(image(item)),
+			Alternate:   path.Base(permalink),
+			Stylesheet:  root + Stylesheet,
+			Platforms:   platforms,
+			Entry:       &e,
+		}
+		if err := write(name, "item", p); err != nil {
+			return written, err
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, Stylesheet), s.stylesheet, 0644); err != nil {
+		return written, err
+	}
+	written = append(written, Stylesheet)
+	return written, nil
+}
+
+// paginate splits items into list pages that mirror the JSON feeds: the
+// single file when pagination is off, otherwise <prefix>_page_N.json. The
+// first page of the main feed is index.html. With stable pagination the
+// newest page mirrors the single file name, which holds a copy of it.
+func (s *Site) paginate(items []feeds.FeedItem, directory feeds.Directory, root, prefix, single string) []page {
+	size := s.options.PageSize
+	if size <= 1 {
+		size = 0
+	}
+	pages := feeds.Paginate(items, size, s.options.Pagination)
+	if len(pages) == 0 {
+		pages = []feeds.Page{{Number: 1}}
+	}
+	head := pages[0].Number
+	stable := s.options.Pagination == feeds.PaginationStable && size > 0
+
+	pageName := func(n int) string {
+		if size == 0 || (stable && n == head) {
+			return single
+		}
+		return fmt.Sprintf("%s_page_%d.json", prefix, n)
+	}
+	htmlName := func(n int) string {
+		if n == head && root == "" {
+			return "index.html"
+		}
+		return strings.TrimSuffix(pageName(n), ".json") + ".html"
+	}
+
+	var result []page
+	for _, pg := range pages {
+		p := page{
+			Site:        s.options,
+			Description: s.options.Description,
+			Root:        root,
+			Type:        "website",
+			Alternate:   pageName(pg.Number),
+			Stylesheet:  root + Stylesheet,
+			Page:        pg.Number,
+			TotalPages:  len(pages),
+		}
+		p.file = htmlName(pg.Number)
+		if root != "" {
+			p.file = path.Join("platforms", p.file)
+		}
+		p.URL = s.absolute(p.file)
+		if pg.Older > 0 {
+			p.Next = htmlName(pg.Older)
+		}
+		if pg.Newer > 0 {
+			p.Prev = htmlName(pg.Newer)
+		}
+		for _, item := range pg.Items {
+			p.Entries = append(p.Entries, s.entry(item, directory, root))
+			if p.Image == "" {
+				p.Image = s.absolute(image(item))
+			}
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+func (s *Site) entry(item feeds.FeedItem, directory feeds.Directory, root string) entry {
+	e := entry{FeedItem: item}
+	if author, ok := directory.Authors[item.AuthorID]; ok {
+		e.Author = &author
+	}
+	if source, ok := directory.Sources[item.SourceID]; ok {
+		e.Source = &source
+	}
+	if item.Permalink != "" {
+		e.Page = root + strings.TrimSuffix(filepath.ToSlash(item.Permalink), ".json") + ".html"
+	}
+	return e
+}
+
+func (s *Site) platformLinks(items []feeds.FeedItem) []link {
+	if !s.options.PlatformPages {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var links []link
+	for _, item := range items {
+		slug := feeds.TagSlug(item.Platform)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		name := slug + ".html"
+		if s.options.PageSize > 1 && s.options.Pagination != feeds.PaginationStable {
+			name = slug + "_page_1.html"
+		}
+		links = append(links, link{Label: item.Platform, URL: path.Join("platforms", name)})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Label < links[j].Label })
+	return links
+}
+
+// absolute resolves a path in the output directory against BaseURL. Without
+// a BaseURL, or for an empty path, it returns "" for site paths and absolute
+// links unchanged.
+func (s *Site) absolute(ref string) string {
+	if ref == "" {
+		return ""
+	}
+	if u, err := url.Parse(ref); err == nil && u.IsAbs() {
+		return ref
+	}
+	if s.options.BaseURL == "" {
+		return ""
+	}
+	base, err := url.Parse(strings.TrimSuffix(s.options.BaseURL, "/") + "/")
+	if err != nil {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+func (s *Site) render(filename, name string, data page) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := s.templates.ExecuteTemplate(file, name, data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to render %s: %w", filename, err)
+	}
+	return file.Close()
+}
+
+// itemTitle is the title of an item's page: its own title, or the start of
+// its text.
+func itemTitle(item feeds.FeedItem) string {
+	if item.Title != "" {
+		return item.Title
+	}
+	text := firstNonEmpty(item.ContentText, item.PostContent)
+	if title, _ := feeds.Excerpt(strings.Join(strings.Fields(text), " "), 70); title != "" {
+		return title
+	}
+	return item.Platform
+}
+
+func description(item feeds.FeedItem) string {
+	text := firstNonEmpty(item.Summary, item.ContentText, item.PostContent)
+	excerpt, _ := feeds.Excerpt(strings.Join(strings.Fields(text), " "), 200)
+	return excerpt
+}
+
+// image is the picture shown when a page is shared.
+func image(item feeds.FeedItem) string {
+	if item.MediaURL != nil {
+		return *item.MediaURL
+	}
+	if item.LinkPreview != nil {
+		return item.LinkPreview.Image
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// funcs are available to every template.
+var funcs = template.FuncMap{
+	// date formats a timestamp, or returns "" for an unknown one
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format("Jan 2, 2006 15:04 UTC")
+	},
+	// isoDate formats a timestamp for <time datetime>
+	"isoDate": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	},
+	// paragraphs splits plain text into a paragraph per line, as
+	// feeds.HTMLToText ends every block with a single line break
+	"paragraphs": func(text string) []string {
+		var paragraphs []string
+		for _, paragraph := range strings.Split(text, "\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				paragraphs = append(paragraphs, paragraph)
+			}
+		}
+		return paragraphs
+	},
+	// safeHTML sanitizes HTML content so it can be embedded in the page
+	"safeHTML": func(fragment string) template.HTML {
+		return template.HTML(normalize.SanitizeHTML(fragment))
+	},
+	"isImage": func(a feeds.Attachment) bool { return a.IsImage() },
+	"isVideo": func(a feeds.Attachment) bool { return strings.HasPrefix(a.Type, "video/") },
+	"isAudio": func(a feeds.Attachment) bool { return strings.HasPrefix(a.Type, "audio/") },
+}
@@ -0,0 +1,203 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"feed/feeds"
+	"feed/internal/testutil"
+)
+
+func TestSite_Write(t *testing.T) {
+	dir := t.TempDir()
+	image := "https://example.com/gopher.png"
+	items := []feeds.FeedItem{
+		{
+			Platform:    "x",
+			ContentText: "<script>x</script> Go 1.23",
+			Username:    "GoDev",
+			MediaURL:    &image,
+			Media:       []feeds.Attachment{{URL: image, Type: "image/png", Alt: "Gopher"}},
+			Timestamp:   time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC),
+			Permalink:   "items/20250102100000_0.json",
+		},
+		{
+			Platform:    "blog",
+			Title:       "Release notes",
+			ContentText: "A long article about the release.",
+			ContentHTML: `<p onclick="evil()">A long <em>article</em> about the release.</p>`,
+			Username:    "Jane",
+			Timestamp:   time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
+			Permalink:   "items/20250101100000_1.json",
+		},
+		{Platform: "x", ContentText: "Older post", Username: "GoDev", Timestamp: time.Date(2024, 12, 31, 10, 0, 0, 0, time.UTC)},
+	}
+	s, err := New(Options{
+		Title:         "My Feed",
+		BaseURL:       "https://example.github.io/feed",
+		PageSize:      2,
+		PlatformPages: true,
+		Excerpt:       func(item feeds.FeedItem) feeds.FeedItem { return item.Excerpt(30) },
+	})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+
+	written, err := s.Write(dir, items, feeds.BuildDirectory(items))
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	expected := []string{
+		"feed_page_2.html",
+		"index.html",
+		"items/20250101100000_1.html",
+		"items/20250102100000_0.html",
+		"platforms/blog_page_1.html",
+		"platforms/x_page_1.html",
+		"style.css",
+	}
+	sort.Strings(written)
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("Expected files %v, got %v", expected, written)
+	}
+
+	index := testutil.ReadFile(t, dir, "index.html")
+	for _, want := range []string{
+		`<title>My Feed</title>`,
+		`<link rel="alternate" type="application/json" href="feed_page_1.json">`,
+		`<meta property="og:url" content="https://example.github.io/feed/index.html">`,
+		`<meta property="og:image" content="https://example.com/gopher.png">`,
+		`<a rel="next" href="feed_page_2.html">`,
+		`<a href="platforms/blog_page_1.html">blog</a>`,
+		`&lt;script&gt;`,
+		`<a class="more" href="items/20250101100000_1.html">Read more</a>`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("Expected index.html to contain %q", want)
+		}
+	}
+	if strings.Contains(index, "<script>") {
+		t.Errorf("Expected item text to be escaped in index.html")
+	}
+
+	if page2 := testutil.ReadFile(t, dir, "feed_page_2.html"); !strings.Contains(page2, `<a rel="prev" href="index.html">`) || !strings.Contains(page2, "Older post") {
+		t.Errorf("Expected page 2 to hold the oldest item and link back to the index")
+	}
+
+	article := testutil.ReadFile(t, dir, "items/20250101100000_1.html")
+	for _, want := range []string{
+		`<title>Release notes</title>`,
+		`<meta property="og:type" content="article">`,
+		`<link rel="alternate" type="application/json" href="20250101100000_1.json">`,
+		`<link rel="stylesheet" href="../style.css">`,
+		`<p>A long <em>article</em> about the release.</p>`,
+	} {
+		if !strings.Contains(article, want) {
+			t.Errorf("Expected the item page to contain %q", want)
+		}
+	}
+	if strings.Contains(article, "onclick") {
+		t.Errorf("Expected the item page's HTML to be sanitized")
+	}
+}
+
+func TestSite_StablePagination(t *testing.T) {
+	dir := t.TempDir()
+	items := []feeds.FeedItem{
+		{Platform: "x", ContentText: "Go 1.23", Username: "GoDev", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), Permalink: "items/20250102100000_0.json"},
+		{Platform: "blog", Title: "Release notes", ContentText: "About the release.", Username: "Jane", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), Permalink: "items/20250101100000_1.json"},
+		{Platform: "x", ContentText: "Older post", Username: "GoDev", Timestamp: time.Date(2024, 12, 31, 10, 0, 0, 0, time.UTC)},
+	}
+	s, err := New(Options{Title: "My Feed", PageSize: 2, Pagination: feeds.PaginationStable})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	written, err := s.Write(dir, items, feeds.BuildDirectory(items))
+	if err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	expected := []string{"feed_page_1.html", "index.html", "items/20250101100000_1.html", "items/20250102100000_0.html", "style.css"}
+	sort.Strings(written)
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("Expected files %v, got %v", expected, written)
+	}
+
+	// The head holds the newest item and mirrors feed.json; page 1 holds the oldest two
+	index := testutil.ReadFile(t, dir, "index.html")
+	if !strings.Contains(index, `href="feed.json"`) || !strings.Contains(index, `<a rel="next" href="feed_page_1.html">`) || strings.Contains(index, "Older post") {
+		t.Errorf("Expected the index to be the head page, got:\n%s", index)
+	}
+	page1 := testutil.ReadFile(t, dir, "feed_page_1.html")
+	if !strings.Contains(page1, `<a rel="prev" href="index.html">`) || !strings.Contains(page1, "Older post") || !strings.Contains(page1, "Release notes") {
+		t.Errorf("Expected page 1 to hold the oldest items and link to the index, got:\n%s", page1)
+	}
+}
+
+func TestSite_ThemeOverride(t *testing.T) {
+	theme := t.TempDir()
+	override := `{{define "entry"}}<div class="custom">{{.Username}}</div>{{end}}`
+	if err := os.WriteFile(filepath.Join(theme, "entry.html"), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(theme, Stylesheet), []byte("body { color: red; }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	items := []feeds.FeedItem{{Platform: "x", ContentText: "Go 1.23", Username: "GoDev", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)}}
+	s, err := New(Options{Title: "My Feed", ThemeDir: theme})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if _, err := s.Write(dir, items, feeds.BuildDirectory(items)); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	index := testutil.ReadFile(t, dir, "index.html")
+	if !strings.Contains(index, `<div class="custom">GoDev</div>`) || !strings.Contains(index, `href="feed.json"`) {
+		t.Errorf("Expected the overridden entry template in an unpaginated index, got:\n%s", index)
+	}
+	if strings.Contains(index, "og:url") {
+		t.Errorf("Expected no og:url without a base URL")
+	}
+	if css := testutil.ReadFile(t, dir, Stylesheet); css != "body { color: red; }" {
+		t.Errorf("Expected the theme's stylesheet, got %q", css)
+	}
+}
+
+func TestSite_ThreadAndParagraphs(t *testing.T) {
+	dir := t.TempDir()
+	items := feeds.FoldThreads([]feeds.FeedItem{
+		{ID: "x:2", Platform: "x", PostContent: "Second post", ContentText: "Second post", Username: "GoDev", InReplyTo: "x:1", Timestamp: time.Date(2025, 1, 2, 11, 0, 0, 0, time.UTC)},
+		{ID: "x:1", Platform: "x", PostContent: "First post", ContentText: "First post", Username: "GoDev", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{Platform: "blog", ContentText: feeds.HTMLToText("<p>One</p><p>Two</p>"), Username: "Jane", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)},
+	})
+	items[0].Permalink = "items/thread.json"
+	items[1].Permalink = "items/blog.json"
+	s, err := New(Options{Title: "My Feed"})
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if _, err := s.Write(dir, items, feeds.BuildDirectory(items)); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+
+	// The thread's joined content and its parts hold the same text, so only
+	// one of them may be rendered
+	thread := testutil.ReadFile(t, dir, "items/thread.html")
+	for _, post := range []string{"<p>First post</p>", "<p>Second post</p>"} {
+		if count := strings.Count(thread, post); count != 1 {
+			t.Errorf("Expected %q once on the thread page, got %d times", post, count)
+		}
+	}
+	if blog := testutil.ReadFile(t, dir, "items/blog.html"); !strings.Contains(blog, "<p>One</p>") || !strings.Contains(blog, "<p>Two</p>") {
+		t.Errorf("Expected a paragraph per HTML block, got %s", blog)
+	}
+}
@@ -0,0 +1,282 @@
+// Package sqlite exports feed items to a SQLite database for querying with
+// SQL. The database is rebuilt from scratch on every run: items, authors and
+// sources go into tables of their own, media, tags and engagement counts
+// into tables with a row per value, and items_fts indexes the text of the
+// items for full-text search.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	_ "modernc.org/sqlite" // Registers the pure-Go "sqlite" driver
+
+	"feed/feeds"
+)
+
+// File is the name of the database in the output directory.
+const File = "feed.sqlite"
+
+// schema creates the tables. items.position keeps the order of the feed,
+// newest first; timestamps are RFC 3339 in UTC, so they sort as text.
+const schema = `
+CREATE TABLE meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE sources (
+	id       TEXT PRIMARY KEY,
+	platform TEXT NOT NULL,
+	title    TEXT,
+	home_url TEXT,
+	feed_url TEXT,
+	icon_url TEXT
+);
+CREATE TABLE authors (
+	id           TEXT PRIMARY KEY,
+	handle       TEXT,
+	display_name TEXT,
+	avatar_url   TEXT,
+	profile_url  TEXT
+);
+CREATE TABLE items (
+	rowid        INTEGER PRIMARY KEY,
+	id           TEXT,
+	position     INTEGER NOT NULL,
+	platform     TEXT NOT NULL,
+	kind         TEXT,
+	source_id    TEXT REFERENCES sources(id),
+	author_id    TEXT REFERENCES authors(id),
+	title        TEXT,
+	post_content TEXT,
+	content_text TEXT,
+	content_html TEXT,
+	summary      TEXT,
+	language     TEXT,
+	username     TEXT,
+	profile_link TEXT,
+	media_url    TEXT,
+	timestamp    TEXT,
+	interactions INTEGER NOT NULL,
+	in_reply_to  TEXT,
+	quoted_item  TEXT,
+	thread_id    TEXT,
+	permalink    TEXT
+);
+CREATE INDEX items_timestamp ON items(timestamp);
+CREATE INDEX items_author ON items(author_id);
+CREATE INDEX items_source ON items(source_id);
+CREATE TABLE media (
+	item      INTEGER NOT NULL REFERENCES items(rowid),
+	position  INTEGER NOT NULL,
+	url       TEXT NOT NULL,
+	type      TEXT,
+	width     INTEGER,
+	height    INTEGER,
+	duration  REAL,
+	length    INTEGER,
+	alt       TEXT,
+	thumbnail TEXT,
+	PRIMARY KEY (item, position)
+);
+CREATE TABLE tags (
+	item     INTEGER NOT NULL REFERENCES items(rowid),
+	position INTEGER NOT NULL,
+	tag      TEXT NOT NULL,
+	PRIMARY KEY (item, position)
+);
+CREATE INDEX tags_tag ON tags(tag COLLATE NOCASE);
+CREATE TABLE engagement (
+	item   INTEGER NOT NULL REFERENCES items(rowid),
+	metric TEXT NOT NULL,
+	count  INTEGER NOT NULL,
+	PRIMARY KEY (item, metric)
+);
+CREATE VIRTUAL TABLE items_fts USING fts5(
+	title, content_text, summary,
+	content = 'items', content_rowid = 'rowid',
+	tokenize = 'porter unicode61 remove_diacritics 2'
+);
+`
+
+// Write creates the database at filename from items, replacing any file
+// already there. Items keep their full content; the parts of threads are
+// not stored separately.
+func Write(filename string, items []feeds.FeedItem, directory feeds.Directory, generated time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create tables: %w", err)
+	}
+	for _, step := range []func(*sql.Tx) error{
+		func(tx *sql.Tx) error { return insertMeta(tx, generated) },
+		func(tx *sql.Tx) error { return insertSources(tx, directory.Sources) },
+		func(tx *sql.Tx) error { return insertAuthors(tx, directory.Authors) },
+		func(tx *sql.Tx) error { return insertItems(tx, items) },
+	} {
+		if err := step(tx); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO items_fts(items_fts) VALUES ('rebuild')`); err != nil {
+		return fmt.Errorf("failed to build the full-text index: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+func insertMeta(tx *sql.Tx, generated time.Time) error {
+	for key, value := range map[string]string{
+		"schema_version": strconv.Itoa(feeds.SchemaVersion),
+		"generated":      generated.UTC().Format(time.RFC3339),
+	} {
+		if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?)`, key, value); err != nil {
+			return fmt.Errorf("failed to insert meta %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func insertSources(tx *sql.Tx, sources map[string]feeds.Source) error {
+	stmt, err := tx.Prepare(`INSERT INTO sources (id, platform, title, home_url, feed_url, icon_url) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	var ids []string
+	for id := range sources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		s := sources[id]
+		if _, err := stmt.Exec(id, s.Platform, null(s.Title), null(s.HomeURL), null(s.FeedURL), null(s.IconURL)); err != nil {
+			return fmt.Errorf("failed to insert source %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func insertAuthors(tx *sql.Tx, authors map[string]feeds.Author) error {
+	stmt, err := tx.Prepare(`INSERT INTO authors (id, handle, display_name, avatar_url, profile_url) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	var ids []string
+	for id := range authors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		a := authors[id]
+		if _, err := stmt.Exec(id, null(a.Handle), null(a.DisplayName), null(a.AvatarURL), null(a.ProfileURL)); err != nil {
+			return fmt.Errorf("failed to insert author %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func insertItems(tx *sql.Tx, items []feeds.FeedItem) error {
+	stmts := make(map[string]*sql.Stmt)
+	for name, query := range map[string]string{
+		"items": `INSERT INTO items (rowid, id, position, platform, kind, source_id, author_id, title, post_content,
+			content_text, content_html, summary, language, username, profile_link, media_url, timestamp,
+			interactions, in_reply_to, quoted_item, thread_id, permalink)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"media":      `INSERT INTO media (item, position, url, type, width, height, duration, length, alt, thumbnail) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		"tags":       `INSERT INTO tags (item, position, tag) VALUES (?, ?, ?)`,
+		"engagement": `INSERT INTO engagement (item, metric, count) VALUES (?, ?, ?)`,
+	} {
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		stmts[name] = stmt
+	}
+
+	for i, item := range items {
+		row := i + 1
+		var mediaURL, timestamp interface{}
+		if item.MediaURL != nil {
+			mediaURL = *item.MediaURL
+		}
+		if !item.Timestamp.IsZero() {
+			timestamp = item.Timestamp.UTC().Format(time.RFC3339)
+		}
+		if _, err := stmts["items"].Exec(row, null(item.ID), i, item.Platform, null(string(item.Kind)),
+			null(item.SourceID), null(item.AuthorID), null(item.Title), item.PostContent,
+			null(item.ContentText), null(item.ContentHTML), null(item.Summary), null(item.Language),
+			item.Username, null(item.ProfileLink), mediaURL, timestamp,
+			item.Interactions, null(item.InReplyTo), null(item.QuotedItem), null(item.ThreadID), null(item.Permalink)); err != nil {
+			return fmt.Errorf("failed to insert item %d: %w", i, err)
+		}
+
+		for j, m := range item.Media {
+			var duration interface{}
+			if m.Duration != 0 {
+				duration = m.Duration
+			}
+			if _, err := stmts["media"].Exec(row, j, m.URL, null(m.Type), zero(int64(m.Width)), zero(int64(m.Height)),
+				duration, zero(m.Length), null(m.Alt), null(m.Thumbnail)); err != nil {
+				return fmt.Errorf("failed to insert media of item %d: %w", i, err)
+			}
+		}
+		for j, tag := range item.Tags {
+			if _, err := stmts["tags"].Exec(row, j, tag); err != nil {
+				return fmt.Errorf("failed to insert tags of item %d: %w", i, err)
+			}
+		}
+		if item.Engagement != nil {
+			// Zero counts are left out, as in the JSON
+			for metric, count := range item.Engagement.Metrics() {
+				if count == 0 {
+					continue
+				}
+				if _, err := stmts["engagement"].Exec(row, metric, count); err != nil {
+					return fmt.Errorf("failed to insert engagement of item %d: %w", i, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// null stores empty strings as NULL.
+func null(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// zero stores zero numbers, which mean unknown, as NULL.
+func zero(n int64) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
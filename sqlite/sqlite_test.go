@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"feed/feeds"
+)
+
+func openTest(t *testing.T, items []feeds.FeedItem) *sql.DB {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), File)
+	if err := Write(filename, items, feeds.BuildDirectory(items), time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Write returned an error: %v", err)
+	}
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func queryStrings(t *testing.T, db *sql.DB, query string, args ...interface{}) []string {
+	t.Helper()
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		t.Fatalf("Query %q failed: %v", query, err)
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func TestWrite(t *testing.T) {
+	image := "https://example.com/cafe.jpg"
+	db := openTest(t, []feeds.FeedItem{
+		{
+			ID: "x:2", Platform: "x", Kind: "post", ContentText: "Running in the park #Go",
+			Username: "godev", Timestamp: time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), Interactions: 7,
+			Engagement: &feeds.Engagement{Likes: 5, Shares: 2},
+			Tags:       []string{"Go", "running"},
+		},
+		{
+			Platform: "blog", Title: "Café review", ContentText: "A new café opened downtown.",
+			Username: "Jane", Timestamp: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), MediaURL: &image,
+			Media: []feeds.Attachment{{URL: image, Type: "image/jpeg", Width: 800, Height: 600}},
+		},
+	})
+
+	if got := queryStrings(t, db, `SELECT coalesce(id, '') || '|' || platform || '|' || timestamp FROM items ORDER BY position`); !reflect.DeepEqual(got, []string{
+		"x:2|x|2025-01-02T10:00:00Z",
+		"|blog|2025-01-01T10:00:00Z",
+	}) {
+		t.Errorf("Unexpected items: %v", got)
+	}
+	if got := queryStrings(t, db, `SELECT a.display_name FROM items i JOIN authors a ON a.id = i.author_id ORDER BY i.position`); !reflect.DeepEqual(got, []string{"godev", "Jane"}) {
+		t.Errorf("Expected the items' authors, got %v", got)
+	}
+	if got := queryStrings(t, db, `SELECT platform FROM sources ORDER BY id`); !reflect.DeepEqual(got, []string{"blog", "x"}) {
+		t.Errorf("Expected a source per platform, got %v", got)
+	}
+	if got := queryStrings(t, db, `SELECT tag FROM tags WHERE item = (SELECT rowid FROM items WHERE id = 'x:2') ORDER BY position`); !reflect.DeepEqual(got, []string{"Go", "running"}) {
+		t.Errorf("Expected the tags in order, got %v", got)
+	}
+	if got := queryStrings(t, db, `SELECT metric || '=' || count FROM engagement ORDER BY metric`); !reflect.DeepEqual(got, []string{"likes=5", "shares=2"}) {
+		t.Errorf("Expected the non-zero engagement counts, got %v", got)
+	}
+	if got := queryStrings(t, db, `SELECT url || ' ' || width || 'x' || height FROM media`); !reflect.DeepEqual(got, []string{"https://example.com/cafe.jpg 800x600"}) {
+		t.Errorf("Unexpected media: %v", got)
+	}
+	if got := queryStrings(t, db, `SELECT value FROM meta WHERE key = 'schema_version'`); len(got) != 1 || got[0] != "2" {
+		t.Errorf("Expected the schema version in meta, got %v", got)
+	}
+}
+
+func TestWrite_FullTextSearch(t *testing.T) {
+	db := openTest(t, []feeds.FeedItem{
+		{Platform: "x", ContentText: "Running in the park #Go"},
+		{Platform: "blog", Title: "Café review", ContentText: "A new café opened downtown."},
+	})
+
+	// Stemmed and without diacritics: "cafe" finds "café", "run" finds "Running"
+	for query, expected := range map[string][]string{
+		"cafe":          {"blog"},
+		"run":           {"x"},
+		"title:review":  {"blog"},
+		"park OR today": {"x"},
+		"missing":       nil,
+	} {
+		got := queryStrings(t, db, `SELECT i.platform FROM items_fts JOIN items i ON i.rowid = items_fts.rowid WHERE items_fts MATCH ? ORDER BY rank`, query)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %q to match %v, got %v", query, expected, got)
+		}
+	}
+}
+
+func TestWrite_Replaces(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), File)
+	items := []feeds.FeedItem{{ID: "x:2", Platform: "x"}, {ID: "blog:1", Platform: "blog"}}
+	for _, run := range [][]feeds.FeedItem{items, items[1:]} {
+		if err := Write(filename, run, feeds.BuildDirectory(run), time.Now()); err != nil {
+			t.Fatalf("Write returned an error: %v", err)
+		}
+	}
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRow(`SELECT count(*) FROM items`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("Expected the database to hold only the second run's item, got %d items", count)
+	}
+}